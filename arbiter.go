@@ -0,0 +1,242 @@
+package ump
+
+import "math"
+
+const (
+	// contactSlop is the small amount of penetration World.Step tolerates
+	// before it starts correcting positions, to avoid jitter from resolving
+	// every last fraction of overlap.
+	contactSlop float32 = 0.01
+	// biasCoef controls how aggressively World.Step's solver pushes
+	// penetrating bodies apart each step.
+	biasCoef float32 = 0.2
+	// solverIterations is how many passes the sequential-impulse solver runs
+	// per Step; more iterations converge closer to an exact solution.
+	solverIterations = 10
+	// restitutionThreshold is the minimum approach speed a contact needs
+	// before restitution kicks in, so a resting stack doesn't pick up a tiny
+	// bounce every step from its own positional-correction bias.
+	restitutionThreshold float32 = 1
+)
+
+type (
+	// arbiterKey identifies an unordered pair of bodies so the same Arbiter is
+	// found again regardless of which body is passed first.
+	arbiterKey struct {
+		a, b uint32
+	}
+	// Contact is a single point of contact tracked by an Arbiter between
+	// frames. jnAcc and jtAcc are the accumulated normal/tangent impulses
+	// from the last time this contact was solved, reapplied at the start of
+	// the next Step as a warm start.
+	Contact struct {
+		Position    Point
+		Normal      Point
+		Penetration float32
+		jnAcc       float32
+		jtAcc       float32
+		bias        float32
+	}
+	// Arbiter is a persistent record of the contacts between two overlapping
+	// bodies, kept alive across World.Step calls for as long as the bodies
+	// keep overlapping. This is what lets the solver warm-start and produce
+	// stable stacking instead of resolving every contact from scratch.
+	Arbiter struct {
+		BodyA, BodyB *Body
+		Contacts     []*Contact
+		Friction     float32
+		Restitution  float32
+	}
+	// BeginCallback is invoked the first time two bodies are found overlapping.
+	// Returning false tells World.Step to ignore the collision for this step.
+	BeginCallback func(world *World, arb *Arbiter) bool
+	// ArbiterCallback is invoked by World.Step for lifecycle events - PreSolve,
+	// PostSolve, and Separate - on an Arbiter that is (or just stopped being)
+	// active. See World.OnPreSolve, World.OnPostSolve, and World.OnSeparate.
+	ArbiterCallback func(world *World, arb *Arbiter)
+)
+
+func newArbiterKey(a, b uint32) arbiterKey {
+	if a < b {
+		return arbiterKey{a, b}
+	}
+	return arbiterKey{b, a}
+}
+
+func newArbiter(a, b *Body) *Arbiter {
+	return &Arbiter{
+		BodyA:       a,
+		BodyB:       b,
+		Friction:    sqrt32(a.friction * b.friction),
+		Restitution: max(a.restitution, b.restitution),
+	}
+}
+
+// generateContacts produces a single contact point for a and b's overlap.
+// RectShape pairs keep the fast Minkowski-difference-of-two-rects path
+// Body.collide uses for its intersecting-and-not-moving case; any pair
+// involving another Shape goes through the same GJK/EPA narrowphase
+// collideShapes uses, so Step's contacts reflect the bodies' actual geometry
+// instead of their bounding boxes.
+func generateContacts(a, b *Body) []*Contact {
+	_, aIsRect := a.shape.(*RectShape)
+	_, bIsRect := b.shape.(*RectShape)
+	if aIsRect && bIsRect {
+		return generateRectContacts(a, b)
+	}
+	return generateShapeContacts(a, b)
+}
+
+// generateRectContacts uses the same Minkowski-difference AABB math
+// Body.collide uses for the intersecting-and-not-moving case: the nearest
+// corner of the difference gives the minimum-translation vector, which
+// doubles here as the contact normal and penetration depth.
+func generateRectContacts(a, b *Body) []*Contact {
+	diff := a.getDiff(b)
+	if !diff.containsPoint(0, 0) {
+		return nil
+	}
+
+	px, py := diff.getNearestCorner(0, 0)
+	var normal Point
+	var penetration float32
+	if abs(px) < abs(py) {
+		penetration, normal = abs(px), Point{X: sign(px)}
+	} else {
+		penetration, normal = abs(py), Point{Y: sign(py)}
+	}
+
+	position := Point{
+		X: (max(a.x, b.x) + min(a.x+a.w, b.x+b.w)) / 2,
+		Y: (max(a.y, b.y) + min(a.y+a.h, b.y+b.h)) / 2,
+	}
+
+	return []*Contact{{Position: position, Normal: normal, Penetration: penetration}}
+}
+
+// generateShapeContacts is generateContacts' path for any pair that isn't
+// both RectShape: GJK finds whether a's and b's shapes overlap, and EPA turns
+// that into the penetration depth and normal the solver needs, the same way
+// Body.collideShapes does for Move/Project.
+func generateShapeContacts(a, b *Body) []*Contact {
+	overlap, _, _, _, simplex := gjk(a.shape, b.shape)
+	if !overlap {
+		return nil
+	}
+
+	// epa's normal points from a's shape towards b's; generateRectContacts'
+	// convention has Normal point the other way, back towards a.
+	towardB, depth := epa(a.shape, b.shape, simplex)
+	normal := neg(towardB)
+	deepestA := a.shape.Support(towardB)
+	deepestB := b.shape.Support(normal)
+	position := Point{X: (deepestA.X + deepestB.X) / 2, Y: (deepestA.Y + deepestB.Y) / 2}
+
+	return []*Contact{{Position: position, Normal: normal, Penetration: depth}}
+}
+
+// matchContacts carries the accumulated impulses of each old contact over to
+// whichever new contact shares its position, so warmStart has something to
+// reapply instead of starting every contact cold every step.
+func matchContacts(old, fresh []*Contact) {
+	for _, nc := range fresh {
+		for _, oc := range old {
+			if nc.Position == oc.Position {
+				nc.jnAcc, nc.jtAcc = oc.jnAcc, oc.jtAcc
+				break
+			}
+		}
+	}
+}
+
+// prepare computes each contact's target normal velocity once per step,
+// before warmStart or any solving has touched this step's velocities: a
+// positional-correction term plus, if the bodies are approaching faster than
+// restitutionThreshold, a restitution term based on that approach speed.
+// Fixing this target up front (rather than recomputing it every iteration
+// from whatever the current velocity happens to be) is what lets solveVelocity
+// converge instead of oscillating between overshooting and undershooting it.
+func (arb *Arbiter) prepare(dt float32) {
+	a, b := arb.BodyA, arb.BodyB
+	for _, c := range arb.Contacts {
+		n := c.Normal
+		rvx, rvy := a.vx-b.vx, a.vy-b.vy
+		vn := rvx*n.X + rvy*n.Y
+
+		posBias := max(0, c.Penetration-contactSlop) * biasCoef / dt
+		restBias := float32(0)
+		if vn < -restitutionThreshold {
+			restBias = -arb.Restitution * vn
+		}
+		c.bias = posBias + restBias
+	}
+}
+
+// warmStart reapplies each contact's impulses from the previous step before
+// the solver runs, so a resting stack doesn't have to rebuild its supporting
+// impulses from zero every single step.
+func (arb *Arbiter) warmStart() {
+	a, b := arb.BodyA, arb.BodyB
+	for _, c := range arb.Contacts {
+		t := Point{X: -c.Normal.Y, Y: c.Normal.X}
+		impulse := Point{
+			X: c.Normal.X*c.jnAcc + t.X*c.jtAcc,
+			Y: c.Normal.Y*c.jnAcc + t.Y*c.jtAcc,
+		}
+		applyImpulse(a, b, impulse)
+	}
+}
+
+// solveVelocity runs one sequential-impulse pass: for every contact it
+// computes the normal impulse needed to drive the bodies' relative normal
+// velocity towards the target prepare computed, clamps the accumulated
+// normal impulse to stay non-negative, then does the same for the tangent
+// (friction) impulse clamped to Coulomb's cone (|jt| <= friction*jn).
+func (arb *Arbiter) solveVelocity() {
+	a, b := arb.BodyA, arb.BodyB
+	invMassSum := a.invMass() + b.invMass()
+	if invMassSum == 0 {
+		return
+	}
+	effMass := 1 / invMassSum
+
+	for _, c := range arb.Contacts {
+		n := c.Normal
+		t := Point{X: -n.Y, Y: n.X}
+
+		rvx, rvy := a.vx-b.vx, a.vy-b.vy
+		vn := rvx*n.X + rvy*n.Y
+		dPn := effMass * (c.bias - vn)
+		newJn := max(float32(0), c.jnAcc+dPn)
+		dJn := newJn - c.jnAcc
+		c.jnAcc = newJn
+		applyImpulse(a, b, Point{X: n.X * dJn, Y: n.Y * dJn})
+
+		rvx, rvy = a.vx-b.vx, a.vy-b.vy
+		vt := rvx*t.X + rvy*t.Y
+		dPt := -vt * effMass
+		maxFriction := arb.Friction * c.jnAcc
+		newJt := clamp32(c.jtAcc+dPt, -maxFriction, maxFriction)
+		dJt := newJt - c.jtAcc
+		c.jtAcc = newJt
+		applyImpulse(a, b, Point{X: t.X * dJt, Y: t.Y * dJt})
+	}
+}
+
+// applyImpulse nudges a and b's velocities in opposite directions, weighted
+// by their inverse mass, so a static (infinite mass) body never moves.
+func applyImpulse(a, b *Body, impulse Point) {
+	ia, ib := a.invMass(), b.invMass()
+	a.vx += impulse.X * ia
+	a.vy += impulse.Y * ia
+	b.vx -= impulse.X * ib
+	b.vy -= impulse.Y * ib
+}
+
+func sqrt32(v float32) float32 {
+	return float32(math.Sqrt(float64(v)))
+}
+
+func clamp32(v, lo, hi float32) float32 {
+	return max(lo, min(hi, v))
+}
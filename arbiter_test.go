@@ -0,0 +1,104 @@
+package ump
+
+import "testing"
+
+// TestStepSettlesOnFloor drops a box onto a static floor under a constant
+// downward acceleration and checks the sequential-impulse solver converges
+// to a resting contact instead of sinking through the floor or bouncing
+// forever: after enough steps the box should settle with (approximately)
+// zero penetration and near-zero velocity.
+func TestStepSettlesOnFloor(t *testing.T) {
+	world := NewWorld(64)
+
+	floor := world.Add("floor", 0, 100, 200, 20)
+	floor.SetStatic(true)
+
+	box := world.Add("box", 90, 40, 20, 20)
+	box.SetMass(1)
+	box.SetRestitution(0)
+	box.SetFriction(0.2)
+
+	const dt = float32(1.0 / 60.0)
+	const gravity = float32(200)
+	for i := 0; i < 300; i++ {
+		vx, vy := box.Velocity()
+		box.SetVelocity(vx, vy+gravity*dt)
+		world.Step(dt)
+	}
+
+	_, y := box.Position()
+	bottom := y + 20
+	if abs(bottom-100) > 1 {
+		t.Fatalf("box settled at bottom=%v, want ~100 (resting on the floor)", bottom)
+	}
+
+	_, vy := box.Velocity()
+	if abs(vy) > 1 {
+		t.Fatalf("box velocity.y = %v, want ~0 once resting", vy)
+	}
+}
+
+// TestStepWarmStartsAcrossSteps checks that once a box has settled onto a
+// floor under constant gravity, its Arbiter carries a non-zero accumulated
+// normal impulse from one Step to the next (rather than resetting to zero
+// and re-deriving support from scratch every frame) for as long as the
+// resting contact keeps recurring at the same position.
+func TestStepWarmStartsAcrossSteps(t *testing.T) {
+	world := NewWorld(64)
+
+	floor := world.Add("floor", 0, 100, 200, 20)
+	floor.SetStatic(true)
+
+	box := world.Add("box", 90, 40, 20, 20)
+	box.SetMass(1)
+
+	const dt = float32(1.0 / 60.0)
+	const gravity = float32(200)
+	step := func() {
+		vx, vy := box.Velocity()
+		box.SetVelocity(vx, vy+gravity*dt)
+		world.Step(dt)
+	}
+	for i := 0; i < 300; i++ {
+		step()
+	}
+
+	key := newArbiterKey(floor.ID, box.ID)
+	arb, ok := world.arbiters[key]
+	if !ok {
+		t.Fatalf("expected an active arbiter between the settled floor and box")
+	}
+	if len(arb.Contacts) == 0 {
+		t.Fatalf("expected at least one contact between the settled floor and box")
+	}
+	settledPos := arb.Contacts[0].Position
+
+	step()
+	arb, ok = world.arbiters[key]
+	if !ok || len(arb.Contacts) == 0 {
+		t.Fatalf("expected the contact to persist once the box has settled")
+	}
+	if arb.Contacts[0].Position != settledPos {
+		t.Fatalf("contact position moved after settling: %v -> %v", settledPos, arb.Contacts[0].Position)
+	}
+	if arb.Contacts[0].jnAcc == 0 {
+		t.Fatalf("expected a non-zero accumulated normal impulse to warm-start from")
+	}
+}
+
+// TestStepSkipsStaticPairs checks that two overlapping static bodies never
+// produce an arbiter - Step has no business solving contacts that can't move.
+func TestStepSkipsStaticPairs(t *testing.T) {
+	world := NewWorld(64)
+
+	a := world.Add("a", 0, 0, 20, 20)
+	a.SetStatic(true)
+	b := world.Add("b", 10, 10, 20, 20)
+	b.SetStatic(true)
+
+	world.Step(1.0 / 60.0)
+
+	if len(world.arbiters) != 0 {
+		t.Fatalf("expected no arbiters between two static bodies, got %d", len(world.arbiters))
+	}
+}
@@ -6,26 +6,41 @@ import (
 
 var curBodyID uint32
 
-// Body represents a rectangle that will collide with other rectangles/bodies
+// Body represents a shape that will collide with other shapes/bodies. x, y,
+// w, and h are kept as a cached copy of shape.AABB(), refreshed on every
+// move, so the broadphase and the RectShape-vs-RectShape fast path in
+// collide can keep reading them directly instead of calling through shape.
 type Body struct {
-	ID      uint32
-	world   *World
-	tag     string
-	x       float32
-	y       float32
-	w       float32
-	h       float32
-	cells   []*cell
-	static  bool
-	respMap map[string]string
+	ID          uint32
+	world       *World
+	tag         string
+	shape       Shape
+	x           float32
+	y           float32
+	w           float32
+	h           float32
+	cells       []*cell
+	static      bool
+	respMap     map[string]string
+	mass        float32
+	restitution float32
+	friction    float32
+	vx          float32
+	vy          float32
 }
 
 func newBody(world *World, tag string, x, y, w, h float32) *Body {
+	return newBodyFromShape(world, tag, &RectShape{x: x, y: y, w: w, h: h})
+}
+
+func newBodyFromShape(world *World, tag string, shape Shape) *Body {
 	id := atomic.AddUint32(&curBodyID, 1)
+	x, y, w, h := shape.AABB()
 	body := &Body{
 		ID:    id,
 		world: world,
 		tag:   tag,
+		shape: shape,
 		x:     x,
 		y:     y,
 		w:     w,
@@ -34,8 +49,11 @@ func newBody(world *World, tag string, x, y, w, h float32) *Body {
 		respMap: map[string]string{
 			"default": defaultFilter,
 		},
+		mass:     1,
+		friction: 0.2,
 	}
-	body.world.grid.update(body)
+	body.world.broadphase.Insert(body)
+	body.world.bodies[body.ID] = body
 	return body
 }
 
@@ -74,16 +92,16 @@ func (body *Body) Update(x, y float32) {
 	if body.static || (body.x == x && body.y == y) {
 		return
 	}
-	body.x, body.y = x, y
-	body.world.grid.update(body)
+	body.shape.Move(x-body.x, y-body.y)
+	body.x, body.y, body.w, body.h = body.shape.AABB()
+	body.world.broadphase.Update(body)
 }
 
 // Remove will remove this body from the world and will no longer collide with
 // any other bodies.
 func (body *Body) Remove() {
-	for _, c := range body.cells {
-		c.leave(body)
-	}
+	body.world.broadphase.Remove(body)
+	delete(body.world.bodies, body.ID)
 }
 
 func (body *Body) collide(other *Body, goalX, goalY float32) *Collision {
@@ -92,7 +110,6 @@ func (body *Body) collide(other *Body, goalX, goalY float32) *Collision {
 	}
 
 	dx, dy := goalX-body.x, goalY-body.y
-	diff := body.getDiff(other)
 	collision := &Collision{
 		Body:     other,
 		RespType: body.GetResponse(other.tag),
@@ -100,6 +117,31 @@ func (body *Body) collide(other *Body, goalX, goalY float32) *Collision {
 		Move:     Point{X: dx, Y: dy},
 	}
 
+	_, bodyIsRect := body.shape.(*RectShape)
+	_, otherIsRect := other.shape.(*RectShape)
+	if bodyIsRect && otherIsRect {
+		if !body.collideRects(other, dx, dy, collision) {
+			return nil
+		}
+	} else if !body.collideShapes(other, dx, dy, collision) {
+		return nil
+	}
+
+	collision.Touch = Point{
+		X: body.x + dx*collision.Intersection + collision.Normal.X*0.01,
+		Y: body.y + dy*collision.Intersection + collision.Normal.Y*0.01,
+	}
+
+	return collision
+}
+
+// collideRects is the pure-AABB fast path, kept for backward compatibility
+// and speed: the Minkowski difference of two rects is itself a rect, so
+// overlap and the minimum-translation vector fall out of simple arithmetic
+// instead of a general GJK/EPA pass.
+func (body *Body) collideRects(other *Body, dx, dy float32, collision *Collision) bool {
+	diff := body.getDiff(other)
+
 	// intersecting and not moving - use minimum displacement vector
 	if diff.containsPoint(0, 0) && dx == 0 && dy == 0 {
 		px, py := diff.getNearestCorner(0, 0)
@@ -113,16 +155,61 @@ func (body *Body) collide(other *Body, goalX, goalY float32) *Collision {
 	} else {
 		collision.Intersection, collision.Normal.X, collision.Normal.Y = diff.getRayIntersectionFraction(0, 0, dx, dy)
 		if collision.Intersection == inf { //no intersection, no collision
-			return nil
+			return false
 		}
 	}
+	return true
+}
 
-	collision.Touch = Point{
-		X: body.x + dx*collision.Intersection + collision.Normal.X*0.01,
-		Y: body.y + dy*collision.Intersection + collision.Normal.Y*0.01,
+// collideShapes is the general narrowphase for any pair involving a
+// non-RectShape: GJK finds whether the shapes currently overlap, EPA turns
+// that into a penetration depth and normal if they do and aren't moving, and
+// shapeCast sweeps body's shape along (dx, dy) to find when it first touches
+// other's otherwise.
+func (body *Body) collideShapes(other *Body, dx, dy float32, collision *Collision) bool {
+	overlap, _, _, _, simplex := gjk(body.shape, other.shape)
+
+	if overlap && dx == 0 && dy == 0 {
+		normal, depth := epa(body.shape, other.shape, simplex)
+		collision.Intersection = -depth
+		// epa's normal points from body's shape towards other's, same as the
+		// Minkowski difference it was built from (gjk(body.shape, other.shape)).
+		// The rest of this package's convention (see getRayIntersectionFraction's
+		// rayTests table) has Normal point the other way, back towards body.
+		collision.Normal = neg(normal)
+		return true
+	}
+	if dx == 0 && dy == 0 {
+		return false
 	}
 
-	return collision
+	fraction, normal := shapeCast(body.shape, other.shape, dx, dy)
+	if fraction == inf {
+		return false
+	}
+	collision.Intersection = fraction
+	collision.Normal = neg(normal)
+	return true
+}
+
+// closestPoints reports the nearest points between body's and other's shapes,
+// and the distance between them, using the same GJK/EPA narrowphase as
+// collideShapes so overlap and separation share one code path. When the
+// shapes overlap, dist is the negated penetration depth (the minimum
+// translation distance) and pa/pb are the deepest points of each shape along
+// the separating axis rather than a true "closest point" pair, since once
+// two convex shapes interpenetrate there's no single nearest-point pair left
+// to report.
+func (body *Body) closestPoints(other *Body) (pa, pb Point, dist float32, penetrating bool) {
+	overlap, pa, pb, dist, simplex := gjk(body.shape, other.shape)
+	if !overlap {
+		return pa, pb, dist, false
+	}
+
+	normal, depth := epa(body.shape, other.shape, simplex)
+	pa = body.shape.Support(normal)
+	pb = other.shape.Support(neg(normal))
+	return pa, pb, -depth, true
 }
 
 // Calculates the minkowski difference between 2 rects, which is another rect
@@ -144,6 +231,9 @@ func (body *Body) getNearestCorner(px, py float32) (x, y float32) {
 	return nearest(px, body.x, body.x+body.w), nearest(py, body.y, body.y+body.h)
 }
 
+// getRayIntersectionFraction is the AABB slab-style ray test, used both as
+// the fast path for a real RectShape body and, via getDiff, to test the
+// Minkowski difference of two rects (which is itself a rect) in collideRects.
 func (body *Body) getRayIntersectionFraction(ox, oy, dx, dy float32) (fraction, nx, ny float32) {
 	vec := []float32{ox, oy, ox + dx, oy + dy}
 	fraction = inf
@@ -195,6 +285,20 @@ func getRayIntersectionFractionOfFirstRay(vec1, vec2 []float32) float32 {
 	return inf
 }
 
+// raycast reports the fraction along (dx, dy) from (ox, oy) at which this
+// body's shape is first hit, or inf if it's never hit. RectShape bodies use
+// the cheap edge-based getRayIntersectionFraction; any other shape is tested
+// by shape-casting a zero-radius CircleShape (a point) against it.
+func (body *Body) raycast(ox, oy, dx, dy float32) (fraction, nx, ny float32) {
+	if _, isRect := body.shape.(*RectShape); isRect {
+		return body.getRayIntersectionFraction(ox, oy, dx, dy)
+	}
+	point := &CircleShape{x: ox, y: oy}
+	fraction, normal := shapeCast(point, body.shape, dx, dy)
+	n := neg(normal)
+	return fraction, n.X, n.Y
+}
+
 func (body *Body) distanceTo(other *Body) float32 {
 	dx := body.x - other.x + (body.w-other.w)/2
 	dy := body.y - other.y + (body.h-other.h)/2
@@ -224,6 +328,59 @@ func (body *Body) SetStatic(isStatic bool) {
 	body.static = isStatic
 }
 
+// Mass will return the mass of this body, used by World.Step to weigh collision
+// response between bodies. Static bodies are always treated as infinitely massive.
+func (body *Body) Mass() float32 {
+	return body.mass
+}
+
+// SetMass will set the mass of this body. A zero or negative mass is treated as
+// infinite, the same as a static body, by the Step solver.
+func (body *Body) SetMass(mass float32) {
+	body.mass = mass
+}
+
+func (body *Body) invMass() float32 {
+	if body.static || body.mass <= 0 {
+		return 0
+	}
+	return 1 / body.mass
+}
+
+// Restitution will return the bounciness of this body used when two bodies
+// collide during World.Step. 0 means no bounce, 1 means a perfectly elastic
+// bounce.
+func (body *Body) Restitution() float32 {
+	return body.restitution
+}
+
+// SetRestitution will set the bounciness of this body.
+func (body *Body) SetRestitution(restitution float32) {
+	body.restitution = restitution
+}
+
+// Friction will return the friction coefficient of this body used when two
+// bodies collide during World.Step.
+func (body *Body) Friction() float32 {
+	return body.friction
+}
+
+// SetFriction will set the friction coefficient of this body.
+func (body *Body) SetFriction(friction float32) {
+	body.friction = friction
+}
+
+// Velocity will return the current velocity of this body as tracked by World.Step.
+func (body *Body) Velocity() (vx, vy float32) {
+	return body.vx, body.vy
+}
+
+// SetVelocity will set the velocity of this body, which World.Step will
+// integrate into its position and resolve against other bodies each step.
+func (body *Body) SetVelocity(vx, vy float32) {
+	body.vx, body.vy = vx, vy
+}
+
 // GetResponses will return the response map set on this body
 func (body *Body) GetResponses() map[string]string {
 	return body.respMap
@@ -0,0 +1,41 @@
+package ump
+
+// Broadphase is the pluggable collision broadphase for a World. It is
+// responsible for tracking where bodies are in space and answering the
+// coarse "what could this overlap" queries that the narrowphase (Body.collide)
+// then refines. The grid is the default implementation; NewWorldWithBroadphase
+// allows swapping in an alternative such as a dynamic AABB tree.
+type Broadphase interface {
+	// Insert starts tracking a body that was not previously tracked.
+	Insert(body *Body)
+	// Remove stops tracking a body.
+	Remove(body *Body)
+	// Update is called whenever a tracked body's position changes.
+	Update(body *Body)
+	// QueryAABB returns every tracked body whose bounds overlap the given box.
+	QueryAABB(x, y, w, h float32) []*Body
+	// QueryPoint returns every tracked body whose bounds contain the given
+	// point. This is a dedicated method rather than QueryAABB(x, y, 0, 0)
+	// because a zero-area box isn't safe to route through every
+	// implementation's AABB bucketing (see grid.QueryPoint).
+	QueryPoint(x, y float32) []*Body
+	// QuerySegment returns every tracked body whose bounds are touched by the
+	// given line segment.
+	QuerySegment(x1, y1, x2, y2 float32) []*Body
+}
+
+// bodiesInCells flattens and deduplicates the bodies found across a set of
+// cells, preserving the order they were first encountered in.
+func bodiesInCells(cells []*cell) []*Body {
+	dict := make(map[uint32]bool)
+	bodies := []*Body{}
+	for _, c := range cells {
+		for id, body := range c.bodies {
+			if _, ok := dict[id]; !ok {
+				bodies = append(bodies, body)
+				dict[id] = true
+			}
+		}
+	}
+	return bodies
+}
@@ -0,0 +1,354 @@
+package ump
+
+// bvhMargin fattens a leaf's AABB beyond the body's actual bounds so that
+// small movements don't require re-inserting the leaf into the tree.
+const bvhMargin float32 = 4
+
+type bvhAABB struct {
+	x, y, w, h float32
+}
+
+func (a bvhAABB) right() float32  { return a.x + a.w }
+func (a bvhAABB) bottom() float32 { return a.y + a.h }
+
+// perimeter is the surface-area heuristic cost used to pick insertion
+// siblings and decide when a rotation would shrink the tree.
+func (a bvhAABB) perimeter() float32 {
+	return 2 * (a.w + a.h)
+}
+
+func (a bvhAABB) union(b bvhAABB) bvhAABB {
+	x := min(a.x, b.x)
+	y := min(a.y, b.y)
+	r := max(a.right(), b.right())
+	bo := max(a.bottom(), b.bottom())
+	return bvhAABB{x: x, y: y, w: r - x, h: bo - y}
+}
+
+func (a bvhAABB) contains(b bvhAABB) bool {
+	return a.x <= b.x && a.y <= b.y && a.right() >= b.right() && a.bottom() >= b.bottom()
+}
+
+func (a bvhAABB) overlaps(b bvhAABB) bool {
+	return a.x < b.right() && a.right() > b.x && a.y < b.bottom() && a.bottom() > b.y
+}
+
+func (a bvhAABB) overlapsSegment(x1, y1, x2, y2 float32) bool {
+	tmin, tmax := float32(0), float32(1)
+	mins := [2]float32{a.x, a.y}
+	maxs := [2]float32{a.right(), a.bottom()}
+	o := [2]float32{x1, y1}
+	d := [2]float32{x2 - x1, y2 - y1}
+
+	for i := 0; i < 2; i++ {
+		if d[i] == 0 {
+			if o[i] < mins[i] || o[i] > maxs[i] {
+				return false
+			}
+			continue
+		}
+		t1, t2 := (mins[i]-o[i])/d[i], (maxs[i]-o[i])/d[i]
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tmin = max(tmin, t1)
+		tmax = min(tmax, t2)
+		if tmin > tmax {
+			return false
+		}
+	}
+	return true
+}
+
+func fatAABB(body *Body) bvhAABB {
+	return bvhAABB{
+		x: body.x - bvhMargin,
+		y: body.y - bvhMargin,
+		w: body.w + 2*bvhMargin,
+		h: body.h + 2*bvhMargin,
+	}
+}
+
+func tightAABB(body *Body) bvhAABB {
+	return bvhAABB{x: body.x, y: body.y, w: body.w, h: body.h}
+}
+
+type bvhNode struct {
+	parent, left, right *bvhNode
+	body                *Body // nil for internal nodes
+	aabb                bvhAABB
+	height              int
+}
+
+func (n *bvhNode) isLeaf() bool {
+	return n.left == nil
+}
+
+// DynamicTree is a Broadphase implementation backed by a dynamic bounding
+// volume hierarchy: each leaf holds one body's fattened AABB, each internal
+// node stores the union AABB of its children, and the tree rebalances itself
+// with AVL-style rotations after every insert so lookups stay near O(log n)
+// even as bodies are added, removed, and moved.
+type DynamicTree struct {
+	root  *bvhNode
+	nodes map[*Body]*bvhNode
+}
+
+// NewDynamicTree builds an empty dynamic AABB tree broadphase.
+func NewDynamicTree() *DynamicTree {
+	return &DynamicTree{nodes: map[*Body]*bvhNode{}}
+}
+
+// Insert adds a body to the tree with a freshly fattened AABB.
+func (t *DynamicTree) Insert(body *Body) {
+	leaf := &bvhNode{body: body, aabb: fatAABB(body)}
+	t.nodes[body] = leaf
+	t.insertLeaf(leaf)
+}
+
+// Remove takes a body out of the tree.
+func (t *DynamicTree) Remove(body *Body) {
+	leaf, ok := t.nodes[body]
+	if !ok {
+		return
+	}
+	delete(t.nodes, body)
+	t.removeLeaf(leaf)
+}
+
+// Update re-inserts a body's leaf only if it has escaped its fat AABB.
+func (t *DynamicTree) Update(body *Body) {
+	leaf, ok := t.nodes[body]
+	if !ok {
+		t.Insert(body)
+		return
+	}
+	tight := tightAABB(body)
+	if leaf.aabb.contains(tight) {
+		return
+	}
+	t.removeLeaf(leaf)
+	leaf.aabb = fatAABB(body)
+	t.insertLeaf(leaf)
+}
+
+// QueryAABB returns every body whose fat AABB overlaps the given box.
+func (t *DynamicTree) QueryAABB(x, y, w, h float32) []*Body {
+	box := bvhAABB{x: x, y: y, w: w, h: h}
+	bodies := []*Body{}
+	if t.root == nil {
+		return bodies
+	}
+	stack := []*bvhNode{t.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !node.aabb.overlaps(box) {
+			continue
+		}
+		if node.isLeaf() {
+			bodies = append(bodies, node.body)
+		} else {
+			stack = append(stack, node.left, node.right)
+		}
+	}
+	return bodies
+}
+
+// QueryPoint returns every body whose fat AABB contains the given point.
+func (t *DynamicTree) QueryPoint(x, y float32) []*Body {
+	return t.QueryAABB(x, y, 0, 0)
+}
+
+// QuerySegment returns every body whose fat AABB is touched by the given segment.
+func (t *DynamicTree) QuerySegment(x1, y1, x2, y2 float32) []*Body {
+	bodies := []*Body{}
+	if t.root == nil {
+		return bodies
+	}
+	stack := []*bvhNode{t.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !node.aabb.overlapsSegment(x1, y1, x2, y2) {
+			continue
+		}
+		if node.isLeaf() {
+			bodies = append(bodies, node.body)
+		} else {
+			stack = append(stack, node.left, node.right)
+		}
+	}
+	return bodies
+}
+
+// insertLeaf walks down from the root picking, at each step, whichever child
+// minimizes the surface-area-heuristic cost of housing the new leaf, then
+// grafts the leaf in alongside that sibling and rebalances back up to the root.
+func (t *DynamicTree) insertLeaf(leaf *bvhNode) {
+	if t.root == nil {
+		t.root = leaf
+		return
+	}
+
+	sibling := t.root
+	for !sibling.isLeaf() {
+		left, right := sibling.left, sibling.right
+		combined := sibling.aabb.union(leaf.aabb)
+		cost := combined.perimeter()
+		inheritCost := cost - sibling.aabb.perimeter()
+
+		costOf := func(child *bvhNode) float32 {
+			c := child.aabb.union(leaf.aabb).perimeter() + inheritCost
+			if !child.isLeaf() {
+				c -= child.aabb.perimeter()
+			}
+			return c
+		}
+		costLeft, costRight := costOf(left), costOf(right)
+
+		if cost < costLeft && cost < costRight {
+			break
+		}
+		if costLeft < costRight {
+			sibling = left
+		} else {
+			sibling = right
+		}
+	}
+
+	oldParent := sibling.parent
+	newParent := &bvhNode{
+		parent: oldParent,
+		aabb:   sibling.aabb.union(leaf.aabb),
+		height: sibling.height + 1,
+	}
+	if oldParent != nil {
+		if oldParent.left == sibling {
+			oldParent.left = newParent
+		} else {
+			oldParent.right = newParent
+		}
+	} else {
+		t.root = newParent
+	}
+	newParent.left, newParent.right = sibling, leaf
+	sibling.parent, leaf.parent = newParent, newParent
+
+	t.fixupFrom(newParent)
+}
+
+func (t *DynamicTree) removeLeaf(leaf *bvhNode) {
+	if leaf == t.root {
+		t.root = nil
+		return
+	}
+
+	parent := leaf.parent
+	grandparent := parent.parent
+	var sibling *bvhNode
+	if parent.left == leaf {
+		sibling = parent.right
+	} else {
+		sibling = parent.left
+	}
+
+	if grandparent == nil {
+		t.root = sibling
+		sibling.parent = nil
+		return
+	}
+
+	if grandparent.left == parent {
+		grandparent.left = sibling
+	} else {
+		grandparent.right = sibling
+	}
+	sibling.parent = grandparent
+
+	t.fixupFrom(grandparent)
+}
+
+// fixupFrom walks from node up to the root, rebalancing and refreshing the
+// cached height/AABB of every ancestor the insert or removal touched.
+func (t *DynamicTree) fixupFrom(node *bvhNode) {
+	for node != nil {
+		node = t.balance(node)
+		node.height = 1 + max32int(node.left.height, node.right.height)
+		node.aabb = node.left.aabb.union(node.right.aabb)
+		node = node.parent
+	}
+}
+
+// balance performs a single AVL-style rotation if node's children differ in
+// height by more than one, and returns the node now occupying node's old slot.
+func (t *DynamicTree) balance(node *bvhNode) *bvhNode {
+	if node.isLeaf() {
+		return node
+	}
+
+	left, right := node.left, node.right
+	balanceFactor := right.height - left.height
+
+	if balanceFactor > 1 {
+		return t.rotate(node, right, left)
+	}
+	if balanceFactor < -1 {
+		return t.rotate(node, left, right)
+	}
+	return node
+}
+
+// rotate hoists heavy (the taller child of node) up into node's slot, moving
+// whichever of heavy's children best pairs with light (node's other child)
+// down to take heavy's old place.
+func (t *DynamicTree) rotate(node, heavy, light *bvhNode) *bvhNode {
+	a, b := heavy.left, heavy.right
+
+	heavy.parent = node.parent
+	if node.parent != nil {
+		if node.parent.left == node {
+			node.parent.left = heavy
+		} else {
+			node.parent.right = heavy
+		}
+	} else {
+		t.root = heavy
+	}
+
+	var keep, swap *bvhNode
+	if a.height > b.height {
+		keep, swap = a, b
+	} else {
+		keep, swap = b, a
+	}
+
+	if heavy == node.right {
+		heavy.left, heavy.right = node, keep
+	} else {
+		heavy.left, heavy.right = keep, node
+	}
+	node.parent = heavy
+	_ = light
+
+	if heavy.left == node {
+		node.left, node.right = light, swap
+	} else {
+		node.left, node.right = swap, light
+	}
+	light.parent, swap.parent = node, node
+
+	node.height = 1 + max32int(node.left.height, node.right.height)
+	node.aabb = node.left.aabb.union(node.right.aabb)
+	heavy.height = 1 + max32int(heavy.left.height, heavy.right.height)
+	heavy.aabb = heavy.left.aabb.union(heavy.right.aabb)
+
+	return heavy
+}
+
+func max32int(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
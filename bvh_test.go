@@ -0,0 +1,102 @@
+package ump
+
+import "testing"
+
+func bodyAt(t *testing.T, tree *DynamicTree, x, y, w, h float32) *Body {
+	t.Helper()
+	world := NewWorldWithBroadphase(tree)
+	return world.Add("body", x, y, w, h)
+}
+
+func TestDynamicTreeInsertAndQueryAABB(t *testing.T) {
+	tree := NewDynamicTree()
+	a := bodyAt(t, tree, 0, 0, 10, 10)
+	b := bodyAt(t, tree, 100, 100, 10, 10)
+	c := bodyAt(t, tree, 200, 0, 10, 10)
+
+	found := tree.QueryAABB(-5, -5, 20, 20)
+	if len(found) != 1 || found[0] != a {
+		t.Fatalf("expected only a near the origin, got %v", found)
+	}
+
+	found = tree.QueryAABB(90, 90, 30, 30)
+	if len(found) != 1 || found[0] != b {
+		t.Fatalf("expected only b near (100,100), got %v", found)
+	}
+
+	found = tree.QueryAABB(195, -5, 20, 20)
+	if len(found) != 1 || found[0] != c {
+		t.Fatalf("expected only c near (200,0), got %v", found)
+	}
+
+	found = tree.QueryAABB(-10, -10, 230, 120)
+	if len(found) != 3 {
+		t.Fatalf("expected all 3 bodies inside a box spanning all of them, got %d", len(found))
+	}
+}
+
+func TestDynamicTreeRemove(t *testing.T) {
+	tree := NewDynamicTree()
+	a := bodyAt(t, tree, 0, 0, 10, 10)
+	b := bodyAt(t, tree, 5, 5, 10, 10)
+
+	tree.Remove(a)
+
+	found := tree.QueryAABB(-5, -5, 30, 30)
+	if len(found) != 1 || found[0] != b {
+		t.Fatalf("expected only b to remain after removing a, got %v", found)
+	}
+
+	// Removing a body not in the tree (or removing twice) must not panic.
+	tree.Remove(a)
+}
+
+func TestDynamicTreeUpdateRefitsLeaf(t *testing.T) {
+	tree := NewDynamicTree()
+	body := bodyAt(t, tree, 0, 0, 10, 10)
+
+	body.Update(500, 500)
+
+	if found := tree.QueryAABB(-5, -5, 20, 20); len(found) != 0 {
+		t.Fatalf("expected body to no longer be found at its old position, got %v", found)
+	}
+	found := tree.QueryAABB(495, 495, 20, 20)
+	if len(found) != 1 || found[0] != body {
+		t.Fatalf("expected body to be found at its new position, got %v", found)
+	}
+}
+
+// TestDynamicTreeManyInsertsStayBalanced inserts enough bodies to force
+// several insertLeaf siblings and AVL rotations, then checks every single
+// one is still exactly where it was put - if a rotation ever dropped or
+// misplaced a leaf, one of these point queries would come back empty.
+func TestDynamicTreeManyInsertsStayBalanced(t *testing.T) {
+	tree := NewDynamicTree()
+	world := NewWorldWithBroadphase(tree)
+
+	const n = 200
+	bodies := make([]*Body, n)
+	for i := 0; i < n; i++ {
+		x := float32(i * 7 % 500)
+		y := float32(i * 13 % 500)
+		bodies[i] = world.Add("body", x, y, 1, 1)
+	}
+
+	for i, body := range bodies {
+		x, y := body.Position()
+		found := tree.QueryAABB(x, y, 1, 1)
+		if len(found) == 0 {
+			t.Fatalf("body %d at (%v,%v) not found after %d inserts", i, x, y, n)
+		}
+		matched := false
+		for _, b := range found {
+			if b == body {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			t.Fatalf("body %d not among results querying its own position", i)
+		}
+	}
+}
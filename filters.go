@@ -0,0 +1,40 @@
+package ump
+
+// touchFilter stops the body at the point it first touched the other body,
+// dropping the rest of the movement and any further collisions.
+func touchFilter(world *World, col *Collision, body *Body, goalX, goalY float32) (gx, gy float32, cols []*Collision) {
+	return col.Touch.X, col.Touch.Y, []*Collision{}
+}
+
+// crossFilter ignores the collision entirely and lets the body keep going
+// towards its goal, re-projecting in case anything else is in the way.
+func crossFilter(world *World, col *Collision, body *Body, goalX, goalY float32) (gx, gy float32, cols []*Collision) {
+	return goalX, goalY, world.Project(body, goalX, goalY)
+}
+
+// slideFilter keeps the component of the goal movement that runs parallel to
+// the collision surface and drops the component along the normal, so the
+// body slides along whichever face it hit.
+func slideFilter(world *World, col *Collision, body *Body, goalX, goalY float32) (gx, gy float32, cols []*Collision) {
+	sx, sy := goalX, goalY
+	if col.Normal.X != 0 {
+		sx = col.Touch.X
+	}
+	if col.Normal.Y != 0 {
+		sy = col.Touch.Y
+	}
+	return sx, sy, world.Project(body, sx, sy)
+}
+
+// bounceFilter reflects the remaining movement about the collision normal for
+// whichever axis it hit, so the body bounces off the surface it touched.
+func bounceFilter(world *World, col *Collision, body *Body, goalX, goalY float32) (gx, gy float32, cols []*Collision) {
+	bx, by := goalX, goalY
+	if col.Normal.X != 0 {
+		bx = col.Touch.X + (col.Touch.X - goalX)
+	}
+	if col.Normal.Y != 0 {
+		by = col.Touch.Y + (col.Touch.Y - goalY)
+	}
+	return bx, by, world.Project(body, bx, by)
+}
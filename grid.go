@@ -16,6 +16,54 @@ func newGrid(cellSize int) *grid {
 	}
 }
 
+// Insert adds a body to the grid for the first time. It shares the same
+// bucketing logic as Update since the grid re-buckets a body wholesale on
+// every move rather than tracking a delta.
+func (g *grid) Insert(body *Body) {
+	g.update(body)
+}
+
+// Remove takes a body out of every cell it currently occupies.
+func (g *grid) Remove(body *Body) {
+	for _, c := range body.cells {
+		c.leave(body)
+	}
+	body.cells = []*cell{}
+}
+
+// Update re-buckets a body into the cells its new position overlaps.
+func (g *grid) Update(body *Body) {
+	g.update(body)
+}
+
+// QueryAABB returns the bodies whose cells overlap the given rectangle.
+func (g *grid) QueryAABB(x, y, w, h float32) []*Body {
+	return bodiesInCells(g.cellsInRect(x, y, w, h))
+}
+
+// QueryPoint returns the bodies in the single cell containing (x, y). This
+// exists instead of routing through QueryAABB(x, y, 0, 0): toCellRect spans
+// a rect by ceil(v/cellSize) - floor(v/cellSize), which is zero whenever x
+// or y lands exactly on a cell boundary, so a zero-area box can silently
+// walk no cells at all.
+func (g *grid) QueryPoint(x, y float32) []*Body {
+	cx, cy := g.cellCoordsAt(x, y)
+	row, ok := g.rows[cy]
+	if !ok {
+		return []*Body{}
+	}
+	c, ok := row[cx]
+	if !ok {
+		return []*Body{}
+	}
+	return bodiesInCells([]*cell{c})
+}
+
+// QuerySegment returns the bodies whose cells are touched by the given segment.
+func (g *grid) QuerySegment(x1, y1, x2, y2 float32) []*Body {
+	return bodiesInCells(g.getCellsTouchedBySegment(x1, y1, x2, y2))
+}
+
 func (g *grid) update(body *Body) {
 	for _, c := range body.cells {
 		c.leave(body)
@@ -0,0 +1,29 @@
+package ump
+
+import "testing"
+
+// TestQueryPointOnCellBoundary guards against a regression where routing
+// QueryPoint through QueryAABB(x, y, 0, 0) silently returned nothing: the
+// grid's cell span is ceil(v/cellSize) - floor(v/cellSize), which is zero
+// whenever x or y sits exactly on a cell boundary, so a zero-area box walks
+// no cells at all even though a body covers that point.
+func TestQueryPointOnCellBoundary(t *testing.T) {
+	world := NewWorld(64)
+	world.Add("body", 0, 0, 100, 100)
+
+	// (64, 64) is exactly one cellSize from the origin - a multiple of the
+	// grid's cell size - and still lands inside the 100x100 body.
+	found := world.QueryPoint(64, 64)
+	if len(found) != 1 {
+		t.Fatalf("expected 1 body at (64,64), got %d", len(found))
+	}
+}
+
+func TestQueryPointOutsideAnyBody(t *testing.T) {
+	world := NewWorld(64)
+	world.Add("body", 0, 0, 10, 10)
+
+	if found := world.QueryPoint(500, 500); len(found) != 0 {
+		t.Fatalf("expected no bodies far from anything, got %d", len(found))
+	}
+}
@@ -0,0 +1,306 @@
+package ump
+
+// This file holds the GJK/EPA narrowphase used by Body.collide for any pair
+// of bodies that aren't both RectShape (which keeps the cheaper, exact
+// Minkowski-difference-of-two-rects path). gjk finds whether two shapes
+// overlap and, when they don't, the closest points between them; epa takes
+// the simplex gjk found enclosing the origin and expands it into the
+// penetration depth and normal; shapeCast sweeps a's shape along a motion
+// vector against b's to find the fraction of the movement at which they
+// first touch.
+
+type gjkPoint struct {
+	p    Point // point on the Minkowski difference A - B
+	a, b Point // the support points on A and B that produced p
+}
+
+func support(a, b Shape, dir Point) gjkPoint {
+	sa := a.Support(dir)
+	sb := b.Support(Point{X: -dir.X, Y: -dir.Y})
+	return gjkPoint{p: Point{X: sa.X - sb.X, Y: sa.Y - sb.Y}, a: sa, b: sb}
+}
+
+// gjk reports whether a and b overlap. When they don't, it also returns the
+// closest points between them and the distance. When they do, the returned
+// simplex encloses the origin and is ready for epa to expand.
+//
+// When one of the shapes is curved (CircleShape, or an OrientedBoxShape seen
+// at a grazing angle), the simplex-reduction direction can chase a
+// continuously-sliding support point forever instead of settling, so rather
+// than stop as soon as a step looks like "no more progress", every
+// non-overlapping simplex this produces is scored and the closest one seen
+// across all iterations wins - that converges on the true closest points
+// even while dir itself oscillates.
+func gjk(a, b Shape) (overlap bool, pa, pb Point, dist float32, simplex []gjkPoint) {
+	dir := Point{X: 1}
+	s0 := support(a, b, dir)
+	simplex = []gjkPoint{s0}
+	dir = Point{X: -s0.p.X, Y: -s0.p.Y}
+	if dir.X == 0 && dir.Y == 0 {
+		return true, Point{}, Point{}, 0, simplex
+	}
+
+	bestPa, bestPb, bestDist := closestFromSimplex(simplex)
+	bestSimplex := simplex
+
+	for iter := 0; iter < 64; iter++ {
+		next := support(a, b, dir)
+
+		candidate := append(append([]gjkPoint{}, simplex...), next)
+		var contains bool
+		var reduced []gjkPoint
+		reduced, dir, contains = reduceSimplex(candidate)
+		if contains {
+			return true, Point{}, Point{}, 0, reduced
+		}
+		if dir.X == 0 && dir.Y == 0 {
+			return true, Point{}, Point{}, 0, reduced
+		}
+
+		simplex = reduced
+		pa, pb, dist = closestFromSimplex(simplex)
+		if dist < bestDist {
+			bestPa, bestPb, bestDist, bestSimplex = pa, pb, dist, simplex
+		}
+	}
+
+	return false, bestPa, bestPb, bestDist, bestSimplex
+}
+
+// reduceSimplex drops whichever point of the simplex isn't needed to keep
+// moving towards the origin, and returns the direction to search next.
+func reduceSimplex(simplex []gjkPoint) ([]gjkPoint, Point, bool) {
+	if len(simplex) == 3 {
+		return reduceTriangle(simplex)
+	}
+	return reduceLine(simplex)
+}
+
+func reduceLine(simplex []gjkPoint) ([]gjkPoint, Point, bool) {
+	b, a := simplex[0], simplex[1]
+	ab := sub(b.p, a.p)
+	ao := neg(a.p)
+	if dot(ab, ao) > 0 {
+		return simplex, perpTowards(ab, ao), false
+	}
+	return []gjkPoint{a}, ao, false
+}
+
+func reduceTriangle(simplex []gjkPoint) ([]gjkPoint, Point, bool) {
+	c, b, a := simplex[0], simplex[1], simplex[2]
+	ab := sub(b.p, a.p)
+	ac := sub(c.p, a.p)
+	ao := neg(a.p)
+
+	abPerp := perpAwayFrom(ab, ac)
+	if dot(abPerp, ao) > 0 {
+		return reduceLine([]gjkPoint{b, a})
+	}
+	acPerp := perpAwayFrom(ac, ab)
+	if dot(acPerp, ao) > 0 {
+		return reduceLine([]gjkPoint{c, a})
+	}
+	return simplex, Point{}, true
+}
+
+// perpTowards returns whichever perpendicular of e points the same way as ao.
+func perpTowards(e, ao Point) Point {
+	p := Point{X: -e.Y, Y: e.X}
+	if dot(p, ao) < 0 {
+		return neg(p)
+	}
+	return p
+}
+
+// perpAwayFrom returns whichever perpendicular of e points away from ref.
+func perpAwayFrom(e, ref Point) Point {
+	p := Point{X: -e.Y, Y: e.X}
+	if dot(p, ref) > 0 {
+		return neg(p)
+	}
+	return p
+}
+
+// closestFromSimplex projects the origin onto the final simplex (a point or
+// a line segment) and interpolates the matching points on the original
+// shapes to go with it.
+func closestFromSimplex(simplex []gjkPoint) (pa, pb Point, dist float32) {
+	if len(simplex) == 1 {
+		return simplex[0].a, simplex[0].b, length(simplex[0].p)
+	}
+
+	p0, p1 := simplex[0], simplex[1]
+	e := sub(p1.p, p0.p)
+	t := float32(0)
+	if denom := dot(e, e); denom != 0 {
+		t = dot(neg(p0.p), e) / denom
+	}
+	t = clamp32(t, 0, 1)
+
+	closest := add(p0.p, scale(e, t))
+	pa = lerpPoint(p0.a, p1.a, t)
+	pb = lerpPoint(p0.b, p1.b, t)
+	return pa, pb, length(closest)
+}
+
+// epa expands the origin-enclosing simplex gjk found into the penetration
+// depth and normal of the Minkowski difference's boundary nearest the
+// origin, growing the polytope one support point at a time.
+func epa(a, b Shape, simplex []gjkPoint) (normal Point, depth float32) {
+	polytope := ensureCCW(triangulate(a, b, simplex))
+
+	for iter := 0; iter < 32; iter++ {
+		edgeIndex, edgeNormal, edgeDist := closestEdge(polytope)
+		s := support(a, b, edgeNormal)
+		sDist := dot(edgeNormal, s.p)
+
+		if sDist-edgeDist < 0.0001 {
+			return edgeNormal, edgeDist
+		}
+
+		next := make([]gjkPoint, 0, len(polytope)+1)
+		next = append(next, polytope[:edgeIndex]...)
+		next = append(next, s)
+		next = append(next, polytope[edgeIndex:]...)
+		polytope = next
+	}
+
+	_, edgeNormal, edgeDist := closestEdge(polytope)
+	return edgeNormal, edgeDist
+}
+
+// triangulate grows a 1- or 2-point simplex into a proper triangle so epa
+// always has an enclosing polytope to expand. gjk can hand back a degenerate
+// simplex when the shapes are merely touching rather than truly overlapping
+// (the search direction collapses to zero right at the origin), which is a
+// real case - two resting bodies are touching, not overlapping, most steps.
+func triangulate(a, b Shape, simplex []gjkPoint) []gjkPoint {
+	for len(simplex) < 3 {
+		var dir Point
+		if len(simplex) == 1 {
+			dir = Point{X: 1, Y: 0}
+		} else {
+			e := sub(simplex[1].p, simplex[0].p)
+			dir = Point{X: -e.Y, Y: e.X}
+		}
+		next := support(a, b, dir)
+		if pointsEqual(next.p, simplex[len(simplex)-1].p) {
+			dir = neg(dir)
+			next = support(a, b, dir)
+		}
+		simplex = append(simplex, next)
+	}
+	return simplex
+}
+
+func pointsEqual(a, b Point) bool {
+	return a.X == b.X && a.Y == b.Y
+}
+
+func ensureCCW(simplex []gjkPoint) []gjkPoint {
+	if cross(sub(simplex[1].p, simplex[0].p), sub(simplex[2].p, simplex[0].p)) < 0 {
+		return []gjkPoint{simplex[0], simplex[2], simplex[1]}
+	}
+	return simplex
+}
+
+// closestEdge finds the polytope edge nearest the origin, returning the
+// index of its second vertex (where a new support point would be inserted),
+// its outward normal, and the origin's distance to it.
+func closestEdge(polytope []gjkPoint) (index int, normal Point, dist float32) {
+	dist = inf
+	for i := range polytope {
+		j := (i + 1) % len(polytope)
+		e := sub(polytope[j].p, polytope[i].p)
+		n := normalize(Point{X: e.Y, Y: -e.X})
+		d := dot(n, polytope[i].p)
+		if d < dist {
+			dist, normal, index = d, n, j
+		}
+	}
+	return index, normal, dist
+}
+
+// translatedShape is an internal Shape wrapper used by shapeCast to test an
+// inner shape as though it had already moved by (dx, dy), without mutating
+// the real shape or the Body it belongs to.
+type translatedShape struct {
+	inner  Shape
+	dx, dy float32
+}
+
+func (s *translatedShape) AABB() (x, y, w, h float32) {
+	x, y, w, h = s.inner.AABB()
+	return x + s.dx, y + s.dy, w, h
+}
+
+func (s *translatedShape) Support(dir Point) Point {
+	p := s.inner.Support(dir)
+	return Point{X: p.X + s.dx, Y: p.Y + s.dy}
+}
+
+func (s *translatedShape) Contains(p Point) bool {
+	return s.inner.Contains(Point{X: p.X - s.dx, Y: p.Y - s.dy})
+}
+
+func (s *translatedShape) Move(dx, dy float32) {
+	s.dx += dx
+	s.dy += dy
+}
+
+// shapeCast sweeps a along (dx, dy) against stationary b using conservative
+// advancement: at each step it measures the current separation with gjk and
+// advances a by at least that much divided by the sweep's speed, which can
+// never overshoot the first contact since nothing can close more distance
+// than that in one step. Returns fraction == inf if a never reaches b within
+// the full (dx, dy) movement.
+func shapeCast(a, b Shape, dx, dy float32) (fraction float32, normal Point) {
+	moveLen := length(Point{X: dx, Y: dy})
+	if moveLen == 0 {
+		return inf, Point{}
+	}
+
+	t := float32(0)
+	for iter := 0; iter < 32; iter++ {
+		swept := &translatedShape{inner: a, dx: t * dx, dy: t * dy}
+		overlap, _, _, dist, simplex := gjk(swept, b)
+		if overlap {
+			n, _ := epa(swept, b, simplex)
+			return t, n
+		}
+		if dist < 0.0001 {
+			_, n, _ := closestEdge(ensureCCW(simplex))
+			return t, n
+		}
+
+		t += dist / moveLen
+		if t > 1 {
+			return inf, Point{}
+		}
+	}
+
+	return inf, Point{}
+}
+
+func dot(a, b Point) float32         { return a.X*b.X + a.Y*b.Y }
+func cross(a, b Point) float32       { return a.X*b.Y - a.Y*b.X }
+func sub(a, b Point) Point           { return Point{X: a.X - b.X, Y: a.Y - b.Y} }
+func add(a, b Point) Point           { return Point{X: a.X + b.X, Y: a.Y + b.Y} }
+func neg(a Point) Point              { return Point{X: -a.X, Y: -a.Y} }
+func scale(a Point, s float32) Point { return Point{X: a.X * s, Y: a.Y * s} }
+
+func length(a Point) float32 {
+	return sqrt32(dot(a, a))
+}
+
+func normalize(a Point) Point {
+	l := length(a)
+	if l == 0 {
+		return Point{X: 1}
+	}
+	return Point{X: a.X / l, Y: a.Y / l}
+}
+
+func lerpPoint(a, b Point, t float32) Point {
+	return Point{X: a.X + (b.X-a.X)*t, Y: a.Y + (b.Y-a.Y)*t}
+}
@@ -0,0 +1,92 @@
+package ump
+
+import "testing"
+
+func TestGJKCirclesOverlap(t *testing.T) {
+	a := NewCircleShape(0, 0, 5)
+	b := NewCircleShape(6, 0, 5)
+
+	overlap, _, _, _, simplex := gjk(a, b)
+	if !overlap {
+		t.Fatalf("circles 6 apart with radius 5 each should overlap")
+	}
+
+	normal, depth := epa(a, b, simplex)
+	wantDepth := float32(4) // 5+5-6
+	if abs(depth-wantDepth) > 0.01 {
+		t.Fatalf("depth = %v, want %v", depth, wantDepth)
+	}
+	// epa's normal points from a towards b, i.e. along +X here.
+	if normal.X < 0.99 || abs(normal.Y) > 0.01 {
+		t.Fatalf("normal = %v, want ~(1,0)", normal)
+	}
+}
+
+func TestGJKCirclesSeparated(t *testing.T) {
+	a := NewCircleShape(0, 0, 5)
+	b := NewCircleShape(20, 0, 5)
+
+	overlap, pa, pb, dist, _ := gjk(a, b)
+	if overlap {
+		t.Fatalf("circles 20 apart with radius 5 each should not overlap")
+	}
+
+	wantDist := float32(10) // 20 - 5 - 5
+	if abs(dist-wantDist) > 0.01 {
+		t.Fatalf("dist = %v, want %v", dist, wantDist)
+	}
+	if abs(pa.X-5) > 0.01 || abs(pb.X-15) > 0.01 {
+		t.Fatalf("closest points = %v, %v, want (5,0) and (15,0)", pa, pb)
+	}
+}
+
+func TestGJKRectVsCircleOverlap(t *testing.T) {
+	rect := NewRectShape(0, 0, 10, 10)
+	circle := NewCircleShape(12, 5, 5) // pokes 3 units into the rect's right edge
+
+	overlap, _, _, _, simplex := gjk(rect, circle)
+	if !overlap {
+		t.Fatalf("circle centered 2 units past the rect's right edge with radius 5 should overlap")
+	}
+
+	normal, depth := epa(rect, circle, simplex)
+	wantDepth := float32(3)
+	if abs(depth-wantDepth) > 0.01 {
+		t.Fatalf("depth = %v, want %v", depth, wantDepth)
+	}
+	if normal.X < 0.99 || abs(normal.Y) > 0.01 {
+		t.Fatalf("normal = %v, want ~(1,0)", normal)
+	}
+}
+
+func TestGJKConvexPolyVsRectOverlap(t *testing.T) {
+	rect := NewRectShape(0, 0, 10, 10)
+	// A triangle poking 2 units into the rect's right edge.
+	tri := NewConvexPolyShape([]Point{{X: 8, Y: 0}, {X: 18, Y: 0}, {X: 8, Y: 10}})
+
+	overlap, _, _, _, simplex := gjk(rect, tri)
+	if !overlap {
+		t.Fatalf("triangle overlapping the rect's right edge should overlap")
+	}
+
+	_, depth := epa(rect, tri, simplex)
+	if depth <= 0 || depth > 2.01 {
+		t.Fatalf("depth = %v, want a shallow penetration around (0, 2]", depth)
+	}
+}
+
+func TestShapeCastStopsAtFirstContact(t *testing.T) {
+	a := NewCircleShape(0, 0, 5)
+	b := NewCircleShape(20, 0, 5)
+
+	fraction, normal := shapeCast(a, b, 20, 0)
+	wantFraction := float32(0.5) // a travels 10 of its 20 units before touching b
+	if abs(fraction-wantFraction) > 0.01 {
+		t.Fatalf("fraction = %v, want %v", fraction, wantFraction)
+	}
+	// shapeCast returns the raw epa(swept-a, b) normal - callers like
+	// collideShapes negate it themselves to point back towards a.
+	if normal.X < 0.99 || abs(normal.Y) > 0.01 {
+		t.Fatalf("normal = %v, want ~(1,0) pointing from a towards b", normal)
+	}
+}
@@ -0,0 +1,194 @@
+package ump
+
+import "math"
+
+// Shape is anything a Body can be collided as. AABB is used to bucket the
+// shape into the broadphase, Support and Contains are what the GJK/EPA
+// narrowphase in collide needs to work with arbitrary convex geometry, and
+// Move repositions the shape rigidly when its Body moves.
+type Shape interface {
+	// AABB returns the shape's axis-aligned bounding box.
+	AABB() (x, y, w, h float32)
+	// Support returns the point on the shape farthest in the given direction.
+	// This is the one primitive GJK and EPA need to work with any convex shape.
+	Support(dir Point) Point
+	// Contains reports whether the point lies on or inside the shape.
+	Contains(p Point) bool
+	// Move rigidly translates the shape by (dx, dy).
+	Move(dx, dy float32)
+}
+
+type (
+	// RectShape is an axis-aligned box, the same shape every Body used to be
+	// restricted to. Body.collide keeps a pure-AABB fast path for RectShape
+	// vs RectShape, since the Minkowski-difference math is cheaper and exact
+	// for boxes and there's no need to go through GJK/EPA for the common case.
+	RectShape struct {
+		x, y, w, h float32
+	}
+	// CircleShape is a circle defined by its center and radius.
+	CircleShape struct {
+		x, y, r float32
+	}
+	// OrientedBoxShape is a box that is not necessarily axis-aligned: a center,
+	// half-extents, and a rotation in radians.
+	OrientedBoxShape struct {
+		x, y, hw, hh, angle float32
+	}
+	// ConvexPolyShape is an arbitrary convex polygon given as a CCW-wound
+	// vertex list in world space.
+	ConvexPolyShape struct {
+		verts []Point
+	}
+)
+
+// NewRectShape builds a RectShape from a top-left position and size.
+func NewRectShape(x, y, w, h float32) *RectShape {
+	return &RectShape{x: x, y: y, w: w, h: h}
+}
+
+func (s *RectShape) AABB() (x, y, w, h float32) {
+	return s.x, s.y, s.w, s.h
+}
+
+func (s *RectShape) Support(dir Point) Point {
+	x, y := s.x, s.y
+	if dir.X > 0 {
+		x = s.x + s.w
+	}
+	if dir.Y > 0 {
+		y = s.y + s.h
+	}
+	return Point{X: x, Y: y}
+}
+
+func (s *RectShape) Contains(p Point) bool {
+	return p.X >= s.x && p.X <= s.x+s.w && p.Y >= s.y && p.Y <= s.y+s.h
+}
+
+func (s *RectShape) Move(dx, dy float32) {
+	s.x += dx
+	s.y += dy
+}
+
+// NewCircleShape builds a CircleShape from a center and radius.
+func NewCircleShape(x, y, r float32) *CircleShape {
+	return &CircleShape{x: x, y: y, r: r}
+}
+
+func (s *CircleShape) AABB() (x, y, w, h float32) {
+	return s.x - s.r, s.y - s.r, 2 * s.r, 2 * s.r
+}
+
+func (s *CircleShape) Support(dir Point) Point {
+	n := normalize(dir)
+	return Point{X: s.x + n.X*s.r, Y: s.y + n.Y*s.r}
+}
+
+func (s *CircleShape) Contains(p Point) bool {
+	dx, dy := p.X-s.x, p.Y-s.y
+	return dx*dx+dy*dy <= s.r*s.r
+}
+
+func (s *CircleShape) Move(dx, dy float32) {
+	s.x += dx
+	s.y += dy
+}
+
+// NewOrientedBoxShape builds an OrientedBoxShape from a center, half-extents,
+// and a rotation in radians.
+func NewOrientedBoxShape(x, y, halfWidth, halfHeight, angle float32) *OrientedBoxShape {
+	return &OrientedBoxShape{x: x, y: y, hw: halfWidth, hh: halfHeight, angle: angle}
+}
+
+func (s *OrientedBoxShape) corners() [4]Point {
+	c, sn := float32(math.Cos(float64(s.angle))), float32(math.Sin(float64(s.angle)))
+	local := [4]Point{{X: -s.hw, Y: -s.hh}, {X: s.hw, Y: -s.hh}, {X: s.hw, Y: s.hh}, {X: -s.hw, Y: s.hh}}
+	for i, p := range local {
+		local[i] = Point{
+			X: s.x + p.X*c - p.Y*sn,
+			Y: s.y + p.X*sn + p.Y*c,
+		}
+	}
+	return local
+}
+
+func (s *OrientedBoxShape) AABB() (x, y, w, h float32) {
+	corners := s.corners()
+	minX, minY := corners[0].X, corners[0].Y
+	maxX, maxY := corners[0].X, corners[0].Y
+	for _, p := range corners[1:] {
+		minX, maxX = min(minX, p.X), max(maxX, p.X)
+		minY, maxY = min(minY, p.Y), max(maxY, p.Y)
+	}
+	return minX, minY, maxX - minX, maxY - minY
+}
+
+func (s *OrientedBoxShape) Support(dir Point) Point {
+	// Rotate dir into the box's local space, pick the obvious local corner,
+	// then rotate that corner back out into world space.
+	c, sn := float32(math.Cos(float64(-s.angle))), float32(math.Sin(float64(-s.angle)))
+	local := Point{X: dir.X*c - dir.Y*sn, Y: dir.X*sn + dir.Y*c}
+	corner := Point{X: sign(local.X) * s.hw, Y: sign(local.Y) * s.hh}
+
+	c2, sn2 := float32(math.Cos(float64(s.angle))), float32(math.Sin(float64(s.angle)))
+	return Point{
+		X: s.x + corner.X*c2 - corner.Y*sn2,
+		Y: s.y + corner.X*sn2 + corner.Y*c2,
+	}
+}
+
+func (s *OrientedBoxShape) Contains(p Point) bool {
+	c, sn := float32(math.Cos(float64(-s.angle))), float32(math.Sin(float64(-s.angle)))
+	dx, dy := p.X-s.x, p.Y-s.y
+	local := Point{X: dx*c - dy*sn, Y: dx*sn + dy*c}
+	return abs(local.X) <= s.hw && abs(local.Y) <= s.hh
+}
+
+func (s *OrientedBoxShape) Move(dx, dy float32) {
+	s.x += dx
+	s.y += dy
+}
+
+// NewConvexPolyShape builds a ConvexPolyShape from a CCW-wound vertex list.
+func NewConvexPolyShape(verts []Point) *ConvexPolyShape {
+	return &ConvexPolyShape{verts: verts}
+}
+
+func (s *ConvexPolyShape) AABB() (x, y, w, h float32) {
+	minX, minY := s.verts[0].X, s.verts[0].Y
+	maxX, maxY := s.verts[0].X, s.verts[0].Y
+	for _, p := range s.verts[1:] {
+		minX, maxX = min(minX, p.X), max(maxX, p.X)
+		minY, maxY = min(minY, p.Y), max(maxY, p.Y)
+	}
+	return minX, minY, maxX - minX, maxY - minY
+}
+
+func (s *ConvexPolyShape) Support(dir Point) Point {
+	best := s.verts[0]
+	bestDot := dot(best, dir)
+	for _, p := range s.verts[1:] {
+		if d := dot(p, dir); d > bestDot {
+			best, bestDot = p, d
+		}
+	}
+	return best
+}
+
+func (s *ConvexPolyShape) Contains(p Point) bool {
+	n := len(s.verts)
+	for i := 0; i < n; i++ {
+		a, b := s.verts[i], s.verts[(i+1)%n]
+		if cross(sub(b, a), sub(p, a)) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *ConvexPolyShape) Move(dx, dy float32) {
+	for i, p := range s.verts {
+		s.verts[i] = Point{X: p.X + dx, Y: p.Y + dy}
+	}
+}
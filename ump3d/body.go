@@ -0,0 +1,285 @@
+package ump3d
+
+import (
+	"sync/atomic"
+)
+
+var curBodyID uint32
+
+// Body represents an axis-aligned box that will collide with other boxes/bodies
+type Body struct {
+	ID      uint32
+	world   *World
+	tag     string
+	x       float32
+	y       float32
+	z       float32
+	w       float32
+	h       float32
+	d       float32
+	cells   []*cell
+	static  bool
+	respMap map[string]string
+}
+
+func newBody(world *World, tag string, x, y, z, w, h, d float32) *Body {
+	id := atomic.AddUint32(&curBodyID, 1)
+	body := &Body{
+		ID:    id,
+		world: world,
+		tag:   tag,
+		x:     x,
+		y:     y,
+		z:     z,
+		w:     w,
+		h:     h,
+		d:     d,
+		cells: []*cell{},
+		respMap: map[string]string{
+			"default": defaultFilter,
+		},
+	}
+	body.world.grid.update(body)
+	return body
+}
+
+// Move moves a body to a new location and will return the point where the body
+// managed to get to (gx, gy, gz). It will also return any collisions that happened
+// inbetween the movements.
+func (body *Body) Move(x, y, z float32) (gx, gy, gz float32, cols []*Collision) {
+	actualX, actualY, actualZ, collisions := body.check(x, y, z)
+	body.Update(actualX, actualY, actualZ)
+	return actualX, actualY, actualZ, collisions
+}
+
+func (body *Body) check(goalX, goalY, goalZ float32) (gx, gy, gz float32, cols []*Collision) {
+	collisions := []*Collision{}
+	projectedCols := body.world.Project(body, goalX, goalY, goalZ)
+	visited := map[*Body]bool{body: true}
+
+	for len(projectedCols) > 0 {
+		collision := projectedCols[0]
+		_, seen := visited[collision.Body]
+		response, hasResp := body.world.responses[collision.RespType]
+		if !seen && hasResp {
+			collisions = append(collisions, collision)
+			goalX, goalY, goalZ, projectedCols = response(body.world, collision, body, goalX, goalY, goalZ)
+			visited[collision.Body] = true
+		} else {
+			projectedCols = projectedCols[1:]
+		}
+	}
+
+	return goalX, goalY, goalZ, collisions
+}
+
+// Update changes the position of the body with out checking for collisions
+func (body *Body) Update(x, y, z float32) {
+	if body.static || (body.x == x && body.y == y && body.z == z) {
+		return
+	}
+	body.x, body.y, body.z = x, y, z
+	body.world.grid.update(body)
+}
+
+// Remove will remove this body from the world and will no longer collide with
+// any other bodies.
+func (body *Body) Remove() {
+	for _, c := range body.cells {
+		c.leave(body)
+	}
+}
+
+func (body *Body) collide(other *Body, goalX, goalY, goalZ float32) *Collision {
+	if other == body {
+		return nil
+	}
+
+	dx, dy, dz := goalX-body.x, goalY-body.y, goalZ-body.z
+	diff := body.getDiff(other)
+	collision := &Collision{
+		Body:     other,
+		RespType: body.GetResponse(other.tag),
+		Distance: body.distanceTo(other),
+		Move:     Point{X: dx, Y: dy, Z: dz},
+	}
+
+	// intersecting and not moving - use minimum displacement vector
+	if diff.containsPoint(0, 0, 0) && dx == 0 && dy == 0 && dz == 0 {
+		px, py, pz := diff.getNearestCorner(0, 0, 0)
+		collision.Intersection = -min(body.w, abs(px)) * min(body.h, abs(py)) * min(body.d, abs(pz))
+		// escape along whichever axis has the smallest penetration, zero the rest
+		ax, ay, az := abs(px), abs(py), abs(pz)
+		switch {
+		case ax <= ay && ax <= az:
+			py, pz = 0, 0
+		case ay <= ax && ay <= az:
+			px, pz = 0, 0
+		default:
+			px, py = 0, 0
+		}
+		collision.Normal = Point{X: sign(px), Y: sign(py), Z: sign(pz)}
+	} else {
+		collision.Intersection, collision.Normal.X, collision.Normal.Y, collision.Normal.Z = diff.getRayIntersectionFraction(0, 0, 0, dx, dy, dz)
+		if collision.Intersection == inf { //no intersection, no collision
+			return nil
+		}
+	}
+
+	collision.Touch = Point{
+		X: body.x + dx*collision.Intersection + collision.Normal.X*0.01,
+		Y: body.y + dy*collision.Intersection + collision.Normal.Y*0.01,
+		Z: body.z + dz*collision.Intersection + collision.Normal.Z*0.01,
+	}
+
+	return collision
+}
+
+// Calculates the minkowski difference between 2 boxes, which is another box
+func (body *Body) getDiff(other *Body) *Body {
+	return &Body{
+		x: other.x - body.x - body.w,
+		y: other.y - body.y - body.h,
+		z: other.z - body.z - body.d,
+		w: body.w + other.w,
+		h: body.h + other.h,
+		d: body.d + other.d,
+	}
+}
+
+func (body *Body) containsPoint(px, py, pz float32) bool {
+	return body.x < px && body.x+body.w > px &&
+		body.y < py && body.y+body.h > py &&
+		body.z < pz && body.z+body.d > pz
+}
+
+func (body *Body) getNearestCorner(px, py, pz float32) (x, y, z float32) {
+	return nearest(px, body.x, body.x+body.w),
+		nearest(py, body.y, body.y+body.h),
+		nearest(pz, body.z, body.z+body.d)
+}
+
+// getRayIntersectionFraction uses the standard slab method against the box's
+// six faces: it narrows a [tmin, tmax] interval one axis at a time and tracks
+// the axis that produced the latest (largest) tmin as the hit normal.
+func (body *Body) getRayIntersectionFraction(ox, oy, oz, dx, dy, dz float32) (fraction, nx, ny, nz float32) {
+	mins := [3]float32{body.x, body.y, body.z}
+	maxs := [3]float32{body.x + body.w, body.y + body.h, body.z + body.d}
+	o := [3]float32{ox, oy, oz}
+	d := [3]float32{dx, dy, dz}
+	normal := [3]float32{0, 0, 0}
+
+	tmin, tmax := float32(0), float32(1)
+	hitAxis := -1
+
+	for i := 0; i < 3; i++ {
+		if d[i] == 0 {
+			if o[i] < mins[i] || o[i] > maxs[i] {
+				return inf, 0, 0, 0
+			}
+			continue
+		}
+
+		t1, t2 := (mins[i]-o[i])/d[i], (maxs[i]-o[i])/d[i]
+		s := float32(-1)
+		if t1 > t2 {
+			t1, t2 = t2, t1
+			s = 1
+		}
+		if t1 > tmin {
+			tmin = t1
+			hitAxis = i
+			normal = [3]float32{0, 0, 0}
+			normal[i] = s
+		}
+		tmax = min(tmax, t2)
+		if tmin > tmax {
+			return inf, 0, 0, 0
+		}
+	}
+
+	if hitAxis == -1 {
+		return inf, 0, 0, 0
+	}
+
+	return tmin, normal[0], normal[1], normal[2]
+}
+
+func (body *Body) distanceTo(other *Body) float32 {
+	dx := body.x - other.x + (body.w-other.w)/2
+	dy := body.y - other.y + (body.h-other.h)/2
+	dz := body.z - other.z + (body.d-other.d)/2
+	return dx*dx + dy*dy + dz*dz
+}
+
+// Position will return the current position of the body.
+func (body *Body) Position() (x, y, z float32) {
+	return body.x, body.y, body.z
+}
+
+// Extents will return the position and size of the body
+func (body *Body) Extents() (x, y, z, w, h, d, r, b, f float32) {
+	return body.x, body.y, body.z, body.w, body.h, body.d, body.x + body.w, body.y + body.h, body.z + body.d
+}
+
+// IsStatic will return if the body is a static body or not. See SetStatic for why
+// it would be a static body
+func (body *Body) IsStatic() bool {
+	return body.static
+}
+
+// SetStatic will make this body static which means that other bodies will collide
+// with it but this body will skip collision check. This is good for optimizing
+// your collisions with items like walls and floors.
+func (body *Body) SetStatic(isStatic bool) {
+	body.static = isStatic
+}
+
+// GetResponses will return the response map set on this body
+func (body *Body) GetResponses() map[string]string {
+	return body.respMap
+}
+
+// SetResponses will set a map of responses for a body. This map defines how this
+// body will react to certain collisions. The map is formatted map[object_tag]filter_name
+// By default all items will collide and not resolve. To change the default behaviour
+// use the "default" entry in the response map. For instance on an item that would
+// bounce like a ball you would call `body.SetResponses(map[string]string{"default": "bounce"})
+func (body *Body) SetResponses(respMap map[string]string) {
+	body.respMap = respMap
+}
+
+// GetResponse will return the filter name for the tag passed. If the tag is not
+// defined in the response map then the default reponse will be returned
+func (body *Body) GetResponse(tag string) string {
+	respType, ok := body.respMap[tag]
+	if !ok {
+		respType, _ = body.respMap["default"]
+	}
+	return respType
+}
+
+// SetResponse will set an entry in the response map for the provided tag.
+func (body *Body) SetResponse(tag, resp string) {
+	body.respMap[tag] = resp
+}
+
+// Tag will return the string tag for this body
+func (body *Body) Tag() string {
+	return body.tag
+}
+
+// HasTag will check a list of tags to see if this body matches any of them. This
+// is good for checking groups of object that collide.
+func (body *Body) HasTag(tags ...string) bool {
+	// This is so that when no tags are passed in, all tags are accepted
+	if tags == nil {
+		return true
+	}
+	for _, tag := range tags {
+		if body.tag == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package ump3d
+
+// touchFilter stops the body at the point it first touched the other body,
+// dropping the rest of the movement and any further collisions.
+func touchFilter(world *World, col *Collision, body *Body, goalX, goalY, goalZ float32) (gx, gy, gz float32, cols []*Collision) {
+	return col.Touch.X, col.Touch.Y, col.Touch.Z, []*Collision{}
+}
+
+// crossFilter ignores the collision entirely and lets the body keep going
+// towards its goal, re-projecting in case anything else is in the way.
+func crossFilter(world *World, col *Collision, body *Body, goalX, goalY, goalZ float32) (gx, gy, gz float32, cols []*Collision) {
+	return goalX, goalY, goalZ, world.Project(body, goalX, goalY, goalZ)
+}
+
+// slideFilter keeps the component of the goal movement that runs parallel to
+// the collision surface and drops the component along the normal, so the
+// body slides along whichever face(s) it hit.
+func slideFilter(world *World, col *Collision, body *Body, goalX, goalY, goalZ float32) (gx, gy, gz float32, cols []*Collision) {
+	sx, sy, sz := goalX, goalY, goalZ
+	if col.Normal.X != 0 {
+		sx = col.Touch.X
+	}
+	if col.Normal.Y != 0 {
+		sy = col.Touch.Y
+	}
+	if col.Normal.Z != 0 {
+		sz = col.Touch.Z
+	}
+	return sx, sy, sz, world.Project(body, sx, sy, sz)
+}
+
+// bounceFilter reflects the remaining movement about the collision normal for
+// whichever axes it hit, so the body bounces off the surface(s) it touched.
+func bounceFilter(world *World, col *Collision, body *Body, goalX, goalY, goalZ float32) (gx, gy, gz float32, cols []*Collision) {
+	bx, by, bz := goalX, goalY, goalZ
+	if col.Normal.X != 0 {
+		bx = col.Touch.X + (col.Touch.X - goalX)
+	}
+	if col.Normal.Y != 0 {
+		by = col.Touch.Y + (col.Touch.Y - goalY)
+	}
+	if col.Normal.Z != 0 {
+		bz = col.Touch.Z + (col.Touch.Z - goalZ)
+	}
+	return bx, by, bz, world.Project(body, bx, by, bz)
+}
@@ -0,0 +1,154 @@
+package ump3d
+
+import (
+	"math"
+)
+
+// cellCoord keys the sparse grid map. Unlike the 2D grid (a map of rows,
+// each holding a map of cells by column) the 3D grid is sparse along all three
+// axes at once, so a single (cx, cy, cz) tuple is used as the map key.
+type cellCoord struct {
+	x, y, z int
+}
+
+type grid struct {
+	cellSize float32
+	cells    map[cellCoord]*cell
+}
+
+func newGrid(cellSize int) *grid {
+	return &grid{
+		cellSize: float32(cellSize),
+		cells:    make(map[cellCoord]*cell),
+	}
+}
+
+func (g *grid) update(body *Body) {
+	for _, c := range body.cells {
+		c.leave(body)
+	}
+	body.cells = []*cell{}
+	cl, ct, cn, cw, ch, cd := g.toCellBox(body.x, body.y, body.z, body.w, body.h, body.d)
+	for cz := cn; cz <= cn+cd-1; cz++ {
+		for cy := ct; cy <= ct+ch-1; cy++ {
+			for cx := cl; cx <= cl+cw-1; cx++ {
+				g.cellAt(float32(cx), float32(cy), float32(cz), true).enter(body)
+			}
+		}
+	}
+}
+
+func (g *grid) cellsInBox(l, t, n, w, h, d float32) []*cell {
+	cl, ct, cn, cw, ch, cd := g.toCellBox(l, t, n, w, h, d)
+	cells := []*cell{}
+	for cz := cn; cz <= cn+cd-1; cz++ {
+		for cy := ct; cy <= ct+ch-1; cy++ {
+			for cx := cl; cx <= cl+cw-1; cx++ {
+				if c, ok := g.cells[cellCoord{cx, cy, cz}]; ok {
+					cells = append(cells, c)
+				}
+			}
+		}
+	}
+	return cells
+}
+
+func (g *grid) toCellBox(x, y, z, w, h, d float32) (cx, cy, cz, cw, ch, cd int) {
+	cx, cy, cz = g.cellCoordsAt(x, y, z)
+	cr := int(math.Ceil(float64((x + w) / g.cellSize)))
+	cb := int(math.Ceil(float64((y + h) / g.cellSize)))
+	cf := int(math.Ceil(float64((z + d) / g.cellSize)))
+	return cx, cy, cz, cr - cx, cb - cy, cf - cz
+}
+
+func (g *grid) cellCoordsAt(x, y, z float32) (cx, cy, cz int) {
+	return int(math.Floor(float64(x / g.cellSize))),
+		int(math.Floor(float64(y / g.cellSize))),
+		int(math.Floor(float64(z / g.cellSize)))
+}
+
+func (g *grid) cellAt(x, y, z float32, cellCoords bool) *cell {
+	var cx, cy, cz int
+	if cellCoords {
+		cx, cy, cz = int(x), int(y), int(z)
+	} else {
+		cx, cy, cz = g.cellCoordsAt(x, y, z)
+	}
+	key := cellCoord{cx, cy, cz}
+	c, ok := g.cells[key]
+	if !ok {
+		c = &cell{bodies: make(map[uint32]*Body)}
+		g.cells[key] = c
+	}
+	return c
+}
+
+func (g *grid) getCellsTouchedBySegment(x1, y1, z1, x2, y2, z2 float32) []*cell {
+	cells := []*cell{}
+	visited := map[*cell]bool{}
+
+	g.traceRay(x1, y1, z1, x2, y2, z2, func(cx, cy, cz int) {
+		c := g.cellAt(float32(cx), float32(cy), float32(cz), true)
+		if _, found := visited[c]; found {
+			return
+		}
+		visited[c] = true
+		cells = append(cells, c)
+	})
+
+	return cells
+}
+
+func (g *grid) rayStep(t1, t2 float32) (int, float32, float32) {
+	v := t2 - t1
+	delta := g.cellSize / v
+	if v > 0 {
+		return 1, delta, delta * (1.0 - frac(t1/g.cellSize))
+	} else if v < 0 {
+		return -1, -delta, -delta * frac(t1/g.cellSize)
+	} else {
+		return 0, inf, inf
+	}
+}
+
+// traceRay extends the 2D voxel walk (Amanatides & Woo) to the third axis: at
+// every step it advances along whichever of tx, ty, tz is nearest, so the
+// ray is always resolved against the closest cell boundary it would cross.
+//
+// Unlike the 2D grid's traceRay, this does not special-case a tie between
+// two of tx/ty/tz (the ray passing exactly through a cell edge or corner):
+// the switch below always advances a single axis, so only one of the two
+// (or four) cells the ray grazes at that tie is visited. Carrying the 2D
+// fix forward would mean handling three pairwise ties instead of one, so
+// it was left out pending evidence it matters in practice for boxes (as
+// opposed to the 2D grid's point-sampling raycasts).
+func (g *grid) traceRay(x1, y1, z1, x2, y2, z2 float32, f func(cx, cy, cz int)) {
+	cx1, cy1, cz1 := g.cellCoordsAt(x1, y1, z1)
+	cx2, cy2, cz2 := g.cellCoordsAt(x2, y2, z2)
+	stepX, dx, tx := g.rayStep(x1, x2)
+	stepY, dy, ty := g.rayStep(y1, y2)
+	stepZ, dz, tz := g.rayStep(z1, z2)
+	cx, cy, cz := cx1, cy1, cz1
+
+	f(cx, cy, cz)
+
+	for abs(float32(cx-cx2))+abs(float32(cy-cy2))+abs(float32(cz-cz2)) > 1 {
+		switch {
+		case tx < ty && tx < tz:
+			tx += dx
+			cx += stepX
+		case ty < tz:
+			ty += dy
+			cy += stepY
+		default:
+			tz += dz
+			cz += stepZ
+		}
+		f(cx, cy, cz)
+	}
+
+	// If we have not arrived to the last cell, use it
+	if cx != cx2 || cy != cy2 || cz != cz2 {
+		f(cx2, cy2, cz2)
+	}
+}
@@ -0,0 +1,47 @@
+package ump3d
+
+import "math"
+
+var inf = float32(math.Inf(1))
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v float32) float32 {
+	if v < 0 {
+		return -1
+	}
+	if v > 0 {
+		return 1
+	}
+	return 0
+}
+
+func min(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func nearest(v, a, b float32) float32 {
+	if abs(v-a) < abs(v-b) {
+		return a
+	}
+	return b
+}
+
+func frac(v float32) float32 {
+	return v - float32(math.Floor(float64(v)))
+}
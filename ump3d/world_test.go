@@ -0,0 +1,71 @@
+package ump3d
+
+import "testing"
+
+func TestMoveStopsAtCollision(t *testing.T) {
+	world := NewWorld(64)
+	world.Add("wall", 100, 0, 0, 10, 100, 100).SetStatic(true)
+	box := world.Add("box", 0, 0, 0, 10, 10, 10)
+
+	gx, gy, gz, cols := box.Move(200, 0, 0)
+	if len(cols) != 1 {
+		t.Fatalf("expected 1 collision, got %d", len(cols))
+	}
+	if gx >= 100 {
+		t.Fatalf("gx = %v, want stopped before the wall at x=100", gx)
+	}
+	if gy != 0 || gz != 0 {
+		t.Fatalf("gy, gz = %v, %v, want unchanged (0, 0)", gy, gz)
+	}
+}
+
+func TestMoveIgnoresNonOverlappingBody(t *testing.T) {
+	world := NewWorld(64)
+	world.Add("far", 1000, 1000, 1000, 10, 10, 10)
+	box := world.Add("box", 0, 0, 0, 10, 10, 10)
+
+	gx, gy, gz, cols := box.Move(50, 0, 0)
+	if len(cols) != 0 {
+		t.Fatalf("expected no collisions, got %d", len(cols))
+	}
+	if gx != 50 || gy != 0 || gz != 0 {
+		t.Fatalf("position = %v, %v, %v, want 50, 0, 0", gx, gy, gz)
+	}
+}
+
+func TestQuerySegmentOrdersByDistance(t *testing.T) {
+	world := NewWorld(64)
+	far := world.Add("body", 50, 0, 0, 10, 10, 10)
+	near := world.Add("body", 20, 0, 0, 10, 10, 10)
+
+	bodies := world.QuerySegment(0, 5, 5, 100, 5, 5)
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 bodies on the segment, got %d", len(bodies))
+	}
+	if bodies[0] != near || bodies[1] != far {
+		t.Fatalf("expected near before far, got IDs %v then %v", bodies[0].ID, bodies[1].ID)
+	}
+}
+
+// TestTraceRayDropsCellOnAxisTie documents a known divergence from the 2D
+// grid: a ray travelling along the diagonal of a cube of cells crosses the
+// x and y cell boundaries in the same step, but traceRay's switch always
+// resolves a tie in favor of y, so the cell across the x boundary - (1, 0,
+// 0) - is never visited even though the ray passes through its corner. The
+// 2D grid special-cases this tie (see grid.go's traceRay) and visits both;
+// this package does not.
+func TestTraceRayDropsCellOnAxisTie(t *testing.T) {
+	world := NewWorld(10)
+
+	visited := map[cellCoord]bool{}
+	world.grid.traceRay(0, 0, 0, 20, 20, 0, func(cx, cy, cz int) {
+		visited[cellCoord{cx, cy, cz}] = true
+	})
+
+	if !visited[(cellCoord{0, 1, 0})] {
+		t.Fatalf("expected the cell across the y boundary to be visited")
+	}
+	if visited[(cellCoord{1, 0, 0})] {
+		t.Fatalf("cell across the x boundary was visited too - traceRay's tie handling changed, update the doc comment on traceRay")
+	}
+}
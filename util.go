@@ -0,0 +1,53 @@
+package ump
+
+import "math"
+
+var inf = float32(math.Inf(1))
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v float32) float32 {
+	if v < 0 {
+		return -1
+	}
+	if v > 0 {
+		return 1
+	}
+	return 0
+}
+
+func min(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func nearest(v, a, b float32) float32 {
+	if abs(v-a) < abs(v-b) {
+		return a
+	}
+	return b
+}
+
+func frac(v float32) float32 {
+	return v - float32(math.Floor(float64(v)))
+}
+
+// crossProduct is the 2D cross product of (x1, y1) and (x2, y2), used by
+// getRayIntersectionFractionOfFirstRay's line-segment intersection test.
+func crossProduct(x1, y1, x2, y2 float32) float32 {
+	return x1*y2 - y1*x2
+}
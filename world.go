@@ -9,12 +9,19 @@ const defaultFilter = "slide"
 
 type (
 	// World is the virtual world in which all these collisions happen. The world
-	// contains a grid, which contains several cells, which contains collidable bodies.
+	// delegates bucketing and broad queries to a Broadphase (a grid by default),
+	// which contains several cells, which contains collidable bodies.
 	//
 	// A world also has registered responses to filter collisions please see Resp for this.
 	World struct {
-		grid      *grid
-		responses map[string]Resp
+		broadphase Broadphase
+		responses  map[string]Resp
+		bodies     map[uint32]*Body
+		arbiters   map[arbiterKey]*Arbiter
+		begin      BeginCallback
+		preSolve   ArbiterCallback
+		postSolve  ArbiterCallback
+		separate   ArbiterCallback
 	}
 	// Resp is a function that will handle and resolve a collision. For instance
 	// the bound filter will return the bounce goal gx gy, and then project for the
@@ -28,9 +35,19 @@ type (
 // of the tile side size. So in a game where tiles are 32x32, cellSize will be 32,
 // 64 or 128. In more sparse games, it can be higher.
 func NewWorld(cellSize int) *World {
+	return NewWorldWithBroadphase(newGrid(cellSize))
+}
+
+// NewWorldWithBroadphase builds a physics world backed by the given Broadphase
+// instead of the default grid. This is useful when body sizes span many
+// orders of magnitude or the world is effectively unbounded, where a dynamic
+// AABB tree (see NewDynamicTree) behaves better than a uniform grid.
+func NewWorldWithBroadphase(bp Broadphase) *World {
 	world := &World{
-		grid:      newGrid(cellSize),
-		responses: map[string]Resp{},
+		broadphase: bp,
+		responses:  map[string]Resp{},
+		bodies:     map[uint32]*Body{},
+		arbiters:   map[arbiterKey]*Arbiter{},
 	}
 	world.AddResponse("touch", touchFilter)
 	world.AddResponse("cross", crossFilter)
@@ -48,13 +65,20 @@ func (world *World) Add(tag string, left, top, w, h float32) *Body {
 	return newBody(world, tag, left, top, w, h)
 }
 
+// AddShape will create a new Body using shape for its geometry instead of the
+// plain rectangle Add assumes. This is how a CircleShape, OrientedBoxShape,
+// or ConvexPolyShape body gets into the world.
+func (world *World) AddShape(tag string, shape Shape) *Body {
+	return newBodyFromShape(world, tag, shape)
+}
+
 // QueryRect will take the rectangle arguments and return any bodies that are in
 // that rectangle
 //
 // If tags are passed into the query then it will only return the bodies with those
 // tags.
 func (world *World) QueryRect(x, y, w, h float32, tags ...string) []*Body {
-	return world.getBodiesInCells(world.grid.cellsInRect(x, y, w, h), tags...)
+	return filterByTag(world.broadphase.QueryAABB(x, y, w, h), tags...)
 }
 
 // QueryPoint will return any bodies that are underneathe the point.
@@ -63,12 +87,8 @@ func (world *World) QueryRect(x, y, w, h float32, tags ...string) []*Body {
 // tags.
 func (world *World) QueryPoint(x, y float32, tags ...string) []*Body {
 	bodies := []*Body{}
-	c := world.grid.cellAt(x, y, false)
-	if c == nil {
-		return []*Body{}
-	}
-	for _, body := range c.bodies {
-		if body.HasTag(tags...) && body.containsPoint(x, y) {
+	for _, body := range world.broadphase.QueryPoint(x, y) {
+		if body.HasTag(tags...) && body.shape.Contains(Point{X: x, Y: y}) {
 			bodies = append(bodies, body)
 		}
 	}
@@ -82,13 +102,12 @@ func (world *World) QueryPoint(x, y float32, tags ...string) []*Body {
 func (world *World) QuerySegment(x1, y1, x2, y2 float32, tags ...string) []*Body {
 	bodies := []*Body{}
 	visited := map[*Body]bool{}
-	cells := world.grid.getCellsTouchedBySegment(x1, y1, x2, y2)
-	bodiesOnSegment := world.getBodiesInCells(cells)
+	bodiesOnSegment := world.broadphase.QuerySegment(x1, y1, x2, y2)
 	distances := map[uint32]float32{}
 	for _, body := range bodiesOnSegment {
 		if _, ok := visited[body]; !ok && body.HasTag(tags...) {
 			visited[body] = true
-			fraction, _, _ := body.getRayIntersectionFraction(x1, y1, x2-x1, y2-y1)
+			fraction, _, _ := body.raycast(x1, y1, x2-x1, y2-y1)
 			if fraction != inf {
 				bodies = append(bodies, body)
 				distances[body.ID] = fraction
@@ -103,18 +122,14 @@ func (world *World) QuerySegment(x1, y1, x2, y2 float32, tags ...string) []*Body
 	return bodies
 }
 
-func (world *World) getBodiesInCells(cells []*cell, tags ...string) []*Body {
-	dict := make(map[uint32]bool)
-	bodies := []*Body{}
-	for _, c := range cells {
-		for id, body := range c.bodies {
-			if _, ok := dict[id]; !ok && body.HasTag(tags...) {
-				bodies = append(bodies, body)
-				dict[id] = true
-			}
+func filterByTag(bodies []*Body, tags ...string) []*Body {
+	filtered := []*Body{}
+	for _, body := range bodies {
+		if body.HasTag(tags...) {
+			filtered = append(filtered, body)
 		}
 	}
-	return bodies
+	return filtered
 }
 
 // Project will project the goal location of the provided body but not move it.
@@ -129,7 +144,7 @@ func (world *World) Project(body *Body, goalX, goalY float32) []*Collision {
 	tb := float32(math.Max(float64(goalY+body.h), float64(body.y+body.h)))
 
 	visited := map[*Body]bool{}
-	bodies := world.getBodiesInCells(world.grid.cellsInRect(tl, tt, tr-tl, tb-tt))
+	bodies := world.broadphase.QueryAABB(tl, tt, tr-tl, tb-tt)
 	for _, other := range bodies {
 		if _, ok := visited[other]; !ok {
 			visited[other] = true
@@ -144,8 +159,193 @@ func (world *World) Project(body *Body, goalX, goalY float32) []*Collision {
 	return collisions
 }
 
+// ClosestPoints returns the nearest points between a's and b's shapes and the
+// distance between them, using the same GJK/EPA narrowphase as Move/Project.
+// If the bodies overlap, penetrating is true, dist is the negated
+// penetration depth, and pa/pb are the deepest points of each shape along
+// the separating axis rather than a true closest-point pair.
+func (world *World) ClosestPoints(a, b *Body) (pa, pb Point, dist float32, penetrating bool) {
+	return a.closestPoints(b)
+}
+
+// Distance finds the body nearest to body (optionally filtered by tags) and
+// returns it along with its ClosestPoints result. It broadphases with an
+// expanding-ring search: starting from a box around body, it doubles the
+// box on each pass until either every matching body in the world has been
+// considered or the nearest distance found so far is already closer than
+// anything the next, larger ring could contain. nearest is nil if no body
+// matches.
+func (world *World) Distance(body *Body, tags ...string) (nearest *Body, pa, pb Point, dist float32) {
+	remaining := 0
+	for _, other := range world.bodies {
+		if other != body && other.HasTag(tags...) {
+			remaining++
+		}
+	}
+	if remaining == 0 {
+		return nil, Point{}, Point{}, 0
+	}
+
+	cx, cy := body.x+body.w/2, body.y+body.h/2
+	// halfExtent is how much closer body's actual surface can be to a point
+	// than its center is, so it has to be subtracted from radius below: a
+	// body whose AABB doesn't overlap the current ring is only guaranteed to
+	// be farther than radius from body's center, not from body's surface.
+	halfExtent := max(body.w, body.h) / 2
+	radius := max(body.w, body.h)
+	if radius == 0 {
+		radius = 1
+	}
+
+	visited := map[*Body]bool{body: true}
+	dist = inf
+	for {
+		for _, other := range world.broadphase.QueryAABB(cx-radius, cy-radius, radius*2, radius*2) {
+			if visited[other] || !other.HasTag(tags...) {
+				continue
+			}
+			visited[other] = true
+			remaining--
+			candPa, candPb, candDist, _ := body.closestPoints(other)
+			if nearest == nil || candDist < dist {
+				nearest, pa, pb, dist = other, candPa, candPb, candDist
+			}
+		}
+		if remaining <= 0 || (nearest != nil && dist <= radius-halfExtent) {
+			break
+		}
+		radius *= 2
+	}
+
+	return nearest, pa, pb, dist
+}
+
 // AddResponse will add a new filter response for this world. This is helpful if
 // you are creating custom reactions in your world.
 func (world *World) AddResponse(name string, response Resp) {
 	world.responses[name] = response
 }
+
+// OnBegin sets the callback invoked the first time Step finds two bodies
+// overlapping. Returning false tells the solver to ignore the pair for this
+// step; no arbiter is kept for it, so Step calls the callback again on the
+// next step the pair still overlaps.
+func (world *World) OnBegin(cb BeginCallback) {
+	world.begin = cb
+}
+
+// OnPreSolve sets the callback invoked every step, after contacts are updated
+// but before the impulse solver runs, for each pair still overlapping. This is
+// a good place to inspect or override an Arbiter's Friction/Restitution.
+func (world *World) OnPreSolve(cb ArbiterCallback) {
+	world.preSolve = cb
+}
+
+// OnPostSolve sets the callback invoked every step, after the impulse solver
+// has run and accumulated its impulses, for each pair still overlapping.
+func (world *World) OnPostSolve(cb ArbiterCallback) {
+	world.postSolve = cb
+}
+
+// OnSeparate sets the callback invoked once when two previously-overlapping
+// bodies stop overlapping.
+func (world *World) OnSeparate(cb ArbiterCallback) {
+	world.separate = cb
+}
+
+// Step advances the persistent-contact simulation by dt seconds: it finds
+// every pair of overlapping bodies, updates (or creates) the Arbiter tracking
+// that pair's contacts, runs the sequential-impulse solver to resolve
+// velocities, and integrates bodies by their resulting velocity. This runs
+// alongside, and independently of, Move/Project - Step is for bodies driven by
+// physics (Mass, Restitution, Friction, Velocity), while Move remains the way
+// to drive a body kinematically through the Resp filters.
+func (world *World) Step(dt float32) {
+	if dt <= 0 {
+		return
+	}
+
+	bodyList := make([]*Body, 0, len(world.bodies))
+	for _, body := range world.bodies {
+		bodyList = append(bodyList, body)
+	}
+
+	active := map[arbiterKey]*Arbiter{}
+	seen := map[arbiterKey]bool{}
+	for _, body := range bodyList {
+		// broadphase.QueryAABB narrows candidates to whatever actually shares
+		// space with body instead of scanning every other body in the world.
+		for _, other := range world.broadphase.QueryAABB(body.x, body.y, body.w, body.h) {
+			if other == body || (body.static && other.static) {
+				continue
+			}
+			// Canonicalize by ID, not discovery order: body/other can be
+			// found from either side of the pair, so without this the same
+			// pair could flip which body is "A" from one Step to the next,
+			// flipping the sign of the contact normal out from under the arbiter.
+			a, b := body, other
+			if a.ID > b.ID {
+				a, b = b, a
+			}
+			key := newArbiterKey(a.ID, b.ID)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			contacts := generateContacts(a, b)
+			if len(contacts) == 0 {
+				continue
+			}
+
+			arb, existed := world.arbiters[key]
+			if !existed {
+				arb = newArbiter(a, b)
+				if world.begin != nil && !world.begin(world, arb) {
+					continue
+				}
+			} else {
+				matchContacts(arb.Contacts, contacts)
+			}
+			arb.Contacts = contacts
+			active[key] = arb
+		}
+	}
+
+	for key, arb := range world.arbiters {
+		if _, ok := active[key]; !ok {
+			if world.separate != nil {
+				world.separate(world, arb)
+			}
+			delete(world.arbiters, key)
+		}
+	}
+
+	for key, arb := range active {
+		world.arbiters[key] = arb
+		if world.preSolve != nil {
+			world.preSolve(world, arb)
+		}
+		arb.prepare(dt)
+		arb.warmStart()
+	}
+
+	for i := 0; i < solverIterations; i++ {
+		for _, arb := range active {
+			arb.solveVelocity()
+		}
+	}
+
+	for _, body := range bodyList {
+		if body.static {
+			continue
+		}
+		body.Update(body.x+body.vx*dt, body.y+body.vy*dt)
+	}
+
+	for _, arb := range active {
+		if world.postSolve != nil {
+			world.postSolve(world, arb)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package ump
+
+import "testing"
+
+// TestDistanceExpandsRingUntilSafe guards against a regression where the
+// expanding-ring termination check compared a candidate's distance against
+// the ring's raw radius instead of radius minus the query body's own
+// half-extent. A body just inside the first ring could look "safe enough"
+// to stop on even though a genuinely closer body sat just outside that
+// ring's AABB query - this sets up exactly that case.
+func TestDistanceExpandsRingUntilSafe(t *testing.T) {
+	world := NewWorldWithBroadphase(NewDynamicTree())
+
+	body := world.Add("body", 0, 0, 200, 200)
+	diag := world.Add("diag", 290, 290, 10, 10) // inside the first ring, dist ~127
+	axis := world.Add("axis", 310, 100, 10, 10) // outside the first ring, dist ~110
+
+	nearest, _, _, dist := world.Distance(body)
+	if nearest != axis {
+		t.Fatalf("nearest = %v, want axis (the true closest body)", nearest.Tag())
+	}
+	if dist > 115 {
+		t.Fatalf("dist = %v, want ~110 (distance to axis)", dist)
+	}
+	_ = diag
+}
+
+func TestDistanceNoMatchingBody(t *testing.T) {
+	world := NewWorld(64)
+	body := world.Add("body", 0, 0, 10, 10)
+
+	nearest, _, _, _ := world.Distance(body, "nonexistent-tag")
+	if nearest != nil {
+		t.Fatalf("expected no match, got %v", nearest.Tag())
+	}
+}